@@ -9,9 +9,17 @@ import (
 
 var DB *gorm.DB
 
+// SQLiteDSN returns the connection string for the database file at path.
+// SQLite allows one writer at a time; the busy timeout makes a connection
+// wait up to five seconds for another one's write lock instead of failing
+// straight away with SQLITE_BUSY.
+func SQLiteDSN(path string) string {
+	return path + "?_busy_timeout=5000"
+}
+
 func InitDB() *gorm.DB {
 	var err error
-	DB, err = gorm.Open(sqlite.Open("household_todo.db"), &gorm.Config{})
+	DB, err = gorm.Open(sqlite.Open(SQLiteDSN("household_todo.db")), &gorm.Config{})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
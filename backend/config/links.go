@@ -0,0 +1,23 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// DeepLink builds an app link for the given path. DEEP_LINK_SCHEME defaults to
+// the app's custom scheme; when DEEP_LINK_HOST is set (for example to a domain
+// serving universal/app links) the link becomes scheme://host/path instead.
+func DeepLink(path string) string {
+	scheme := os.Getenv("DEEP_LINK_SCHEME")
+	if scheme == "" {
+		scheme = "householdtodoapp"
+	}
+	host := strings.Trim(os.Getenv("DEEP_LINK_HOST"), "/")
+	path = strings.TrimPrefix(path, "/")
+
+	if host == "" {
+		return scheme + "://" + path
+	}
+	return scheme + "://" + host + "/" + path
+}
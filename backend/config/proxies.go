@@ -0,0 +1,20 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// TrustedProxies lists the reverse proxies, as IPs or CIDRs in the
+// comma-separated TRUSTED_PROXIES, whose X-Forwarded-For headers are
+// believed. Without it no proxy is trusted and the client IP used for rate
+// limits and sessions is the address of the connection itself.
+func TrustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
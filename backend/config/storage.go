@@ -0,0 +1,28 @@
+package config
+
+import (
+	"log"
+	"os"
+
+	"household-todo-backend/storage"
+)
+
+var Storage storage.Storage
+
+// InitStorage sets up where uploaded files are kept. STORAGE_DIR defaults to
+// "uploads" next to the database.
+func InitStorage() storage.Storage {
+	dir := os.Getenv("STORAGE_DIR")
+	if dir == "" {
+		dir = "uploads"
+	}
+
+	local, err := storage.NewLocal(dir)
+	if err != nil {
+		log.Fatal("Failed to set up file storage:", err)
+	}
+	Storage = local
+
+	log.Println("File storage ready in", dir)
+	return Storage
+}
@@ -0,0 +1,383 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"household-todo-backend/config"
+	"household-todo-backend/models"
+	"household-todo-backend/storage"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// attachmentURLTTL is how long a signed download link works
+	attachmentURLTTL = 15 * time.Minute
+
+	// thumbnailSize is the longest side of an image thumbnail in pixels
+	thumbnailSize = 320
+)
+
+// attachmentTypes are the sniffed content types that can be attached.
+// Thumbnails are made for the ones the standard library can decode.
+var attachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      false,
+	"application/pdf": false,
+}
+
+// errQuotaExceeded rejects an upload that would take the household over its
+// storage quota
+var errQuotaExceeded = errors.New("household storage quota exceeded")
+
+type AttachmentController struct {
+	DB *gorm.DB
+}
+
+func NewAttachmentController(db *gorm.DB) *AttachmentController {
+	return &AttachmentController{DB: db}
+}
+
+// GetAttachments lists a task's attachments, newest first, with download
+// links that expire after a while
+func (ac *AttachmentController) GetAttachments(c *gin.Context) {
+	task, ok := ac.findTask(c)
+	if !ok {
+		return
+	}
+
+	var attachments []models.TaskAttachment
+	if err := ac.DB.Where("task_id = ?", task.ID).Preload("Uploader").Order("created_at DESC").Find(&attachments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch attachments"})
+		return
+	}
+	for i := range attachments {
+		signAttachment(c, &attachments[i])
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// UploadAttachment attaches the file in the multipart "file" field to a task
+func (ac *AttachmentController) UploadAttachment(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	task, ok := ac.findTask(c)
+	if !ok {
+		return
+	}
+
+	// Leave room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, models.MaxAttachmentSize+64<<10)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Files can be at most 10 MB"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the \"file\" field"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxAttachmentSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	if len(data) > models.MaxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Files can be at most 10 MB"})
+		return
+	}
+	if len(data) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is empty"})
+		return
+	}
+
+	// The declared type is ignored; only what the content looks like counts
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	thumbnailable, allowed := attachmentTypes[contentType]
+	if !allowed {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Only JPEG, PNG, GIF and WebP images and PDF files can be attached"})
+		return
+	}
+
+	var count int64
+	ac.DB.Model(&models.TaskAttachment{}).Where("task_id = ?", task.ID).Count(&count)
+	if count >= models.MaxTaskAttachments {
+		c.JSON(http.StatusConflict, gin.H{"error": "A task can have at most 20 attachments"})
+		return
+	}
+
+	key := "attachments/" + task.HouseholdID + "/" + utils.GenerateSecureToken(18)
+	attachment := models.TaskAttachment{
+		TaskID:      task.ID,
+		HouseholdID: task.HouseholdID,
+		UploaderID:  userID,
+		FileName:    cleanFileName(header.Filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		StorageKey:  key,
+	}
+
+	// Images that cannot be decoded are still kept, just without a thumbnail
+	var thumbnail []byte
+	if thumbnailable {
+		if img, err := utils.DecodeImage(data); err == nil {
+			attachment.Width = img.Bounds().Dx()
+			attachment.Height = img.Bounds().Dy()
+			thumbnail, _ = utils.EncodeJPEG(utils.FitWithin(img, thumbnailSize), 80)
+		}
+	}
+
+	if err := config.Storage.Put(key, data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return
+	}
+	if thumbnail != nil {
+		attachment.ThumbnailKey = key + "-thumb.jpg"
+		if err := config.Storage.Put(attachment.ThumbnailKey, thumbnail); err != nil {
+			removeAttachmentFiles(&attachment)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+			return
+		}
+	}
+
+	// The row is inserted before usage is summed, so the sum includes this
+	// file. A concurrent upload waits for the insert to commit or roll back
+	// and then counts it too, so two files cannot both fit in the last gap.
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&attachment).Error; err != nil {
+			return err
+		}
+		used, err := models.HouseholdStorageUsed(tx, task.HouseholdID)
+		if err != nil {
+			return err
+		}
+		if used > models.HouseholdStorageQuota {
+			return errQuotaExceeded
+		}
+		return nil
+	})
+	if err != nil {
+		removeAttachmentFiles(&attachment)
+		if err == errQuotaExceeded {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "The household has used all of its storage"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save attachment"})
+		return
+	}
+
+	if err := ac.DB.Preload("Uploader").First(&attachment, "id = ?", attachment.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load attachment"})
+		return
+	}
+	signAttachment(c, &attachment)
+
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// DeleteAttachment removes an attachment. Only whoever uploaded it or an
+// admin can.
+func (ac *AttachmentController) DeleteAttachment(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	task, ok := ac.findTask(c)
+	if !ok {
+		return
+	}
+
+	var attachment models.TaskAttachment
+	if err := ac.DB.Where("id = ? AND task_id = ?", c.Param("attachmentId"), task.ID).First(&attachment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+	if attachment.UploaderID != userID && !models.IsAdmin(ac.DB, userID, task.HouseholdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the uploader or an admin can delete this attachment"})
+		return
+	}
+
+	var files []string
+	if err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		files, err = deleteAttachments(tx, "id = ?", attachment.ID)
+		return err
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attachment"})
+		return
+	}
+	removeFiles(files)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
+
+// GetStorageUsage reports how much of its storage quota a household uses
+func (ac *AttachmentController) GetStorageUsage(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	used, err := models.HouseholdStorageUsed(ac.DB, householdID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch storage usage"})
+		return
+	}
+	var count int64
+	ac.DB.Model(&models.TaskAttachment{}).Where("household_id = ?", householdID).Count(&count)
+
+	c.JSON(http.StatusOK, gin.H{"used": used, "quota": models.HouseholdStorageQuota, "attachments": count})
+}
+
+// DownloadAttachment serves an attachment, or its thumbnail, to anyone with
+// a signed link. Links are handed out by the authenticated endpoints, so
+// image tags and download managers can use them without credentials.
+func (ac *AttachmentController) DownloadAttachment(c *gin.Context) {
+	if !utils.VerifyPathSignature(c.Request.URL.Path, c.Request.URL.Query()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Link is invalid or has expired"})
+		return
+	}
+
+	var attachment models.TaskAttachment
+	if err := ac.DB.Where("id = ?", c.Param("id")).First(&attachment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+		return
+	}
+
+	key, contentType := attachment.StorageKey, attachment.ContentType
+	disposition := "attachment"
+	if strings.HasPrefix(contentType, "image/") {
+		disposition = "inline"
+	}
+	if strings.HasSuffix(c.Request.URL.Path, "/thumbnail") {
+		if attachment.ThumbnailKey == "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment has no thumbnail"})
+			return
+		}
+		key, contentType, disposition = attachment.ThumbnailKey, "image/jpeg", "inline"
+	}
+
+	file, err := config.Storage.Open(key)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'; sandbox")
+	c.Header("Cache-Control", "private, max-age=900")
+	http.ServeContent(c.Writer, c.Request, "", attachment.CreatedAt, file)
+}
+
+// findTask loads the task named in the path from the caller's household
+func (ac *AttachmentController) findTask(c *gin.Context) (*models.Task, bool) {
+	var task models.Task
+	if err := ac.DB.Where("id = ? AND household_id = ?", c.Param("id"), c.GetString("householdID")).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return nil, false
+	}
+	return &task, true
+}
+
+// signAttachment fills in an attachment's download links
+func signAttachment(c *gin.Context, attachment *models.TaskAttachment) {
+	expires := time.Now().Add(attachmentURLTTL)
+	base := "/files/attachments/" + attachment.ID
+	attachment.URL = publicBaseURL(c) + base + "?" + utils.SignPath(base, expires)
+	if attachment.ThumbnailKey != "" {
+		thumbnail := base + "/thumbnail"
+		attachment.ThumbnailURL = publicBaseURL(c) + thumbnail + "?" + utils.SignPath(thumbnail, expires)
+	}
+}
+
+// deleteAttachments removes the attachments matching a condition and
+// returns the storage keys of their files. Files cannot be rolled back, so
+// callers remove them with removeFiles once the transaction has committed.
+func deleteAttachments(tx *gorm.DB, query interface{}, args ...interface{}) ([]string, error) {
+	var attachments []models.TaskAttachment
+	if err := tx.Where(query, args...).Find(&attachments).Error; err != nil {
+		return nil, err
+	}
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+	if err := tx.Where(query, args...).Delete(&models.TaskAttachment{}).Error; err != nil {
+		return nil, err
+	}
+	var keys []string
+	for i := range attachments {
+		keys = append(keys, attachmentFileKeys(&attachments[i])...)
+	}
+	return keys, nil
+}
+
+// attachmentFileKeys lists the storage keys of an attachment's file and
+// thumbnail
+func attachmentFileKeys(attachment *models.TaskAttachment) []string {
+	keys := []string{attachment.StorageKey}
+	if attachment.ThumbnailKey != "" {
+		keys = append(keys, attachment.ThumbnailKey)
+	}
+	return keys
+}
+
+// removeAttachmentFiles deletes an attachment's file and thumbnail from
+// storage
+func removeAttachmentFiles(attachment *models.TaskAttachment) {
+	removeFiles(attachmentFileKeys(attachment))
+}
+
+// removeFiles deletes stored files after the rows pointing at them are gone.
+// Files that are already gone are fine; any other failure only leaves an
+// unreferenced file behind, so it is logged rather than returned.
+func removeFiles(keys []string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := config.Storage.Delete(key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			log.Printf("Failed to remove stored file %s: %v", key, err)
+		}
+	}
+}
+
+// cleanFileName keeps the last element of an uploaded file's name without
+// control characters, for showing and for Content-Disposition
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	if runes := []rune(name); len(runes) > 200 {
+		name = string(runes[:200])
+	}
+	return name
+}
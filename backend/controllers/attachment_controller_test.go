@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"household-todo-backend/config"
+	"household-todo-backend/models"
+	"household-todo-backend/storage"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUploadAttachmentConcurrentlyNearQuota(t *testing.T) {
+	db := newTestDB(t)
+	householdID, userID := newTestHousehold(t, db)
+	if err := utils.LoadSigningKey(); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	local, err := storage.NewLocal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Storage = local
+
+	task := models.Task{Title: "Receipts", CreatorID: userID, HouseholdID: householdID}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// Room for one more file, but not for two
+	const fileSize = 1000
+	if err := db.Create(&models.TaskAttachment{
+		TaskID: task.ID, HouseholdID: householdID, UploaderID: userID,
+		FileName: "old.pdf", ContentType: "application/pdf", StorageKey: "attachments/old",
+		Size: models.HouseholdStorageQuota - fileSize*3/2,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "receipt.pdf")
+	part.Write(append([]byte("%PDF-1.4\n"), bytes.Repeat([]byte{' '}, fileSize-9)...))
+	form.Close()
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/tasks/:id/attachments", authenticated(userID, householdID), NewAttachmentController(db).UploadAttachment)
+
+	// Several rounds, since each one only races if the goroutines overlap
+	for round := 0; round < 5; round++ {
+		var wg sync.WaitGroup
+		start := make(chan struct{})
+		statuses := make([]int, 2)
+		for i := range statuses {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				statuses[i] = serve(r, http.MethodPost, "/tasks/"+task.ID+"/attachments", form.FormDataContentType(), body.String()).Code
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+
+		created, refused := 0, 0
+		for _, status := range statuses {
+			switch status {
+			case http.StatusCreated:
+				created++
+			case http.StatusRequestEntityTooLarge:
+				refused++
+			}
+		}
+		if created != 1 || refused != 1 {
+			t.Fatalf("round %d: statuses = %v, want one 201 and one 413", round, statuses)
+		}
+
+		var uploaded []models.TaskAttachment
+		db.Where("file_name = ?", "receipt.pdf").Find(&uploaded)
+		if len(uploaded) != 1 {
+			t.Fatalf("round %d: %d uploads saved, want 1", round, len(uploaded))
+		}
+		files, _ := filepath.Glob(filepath.Join(dir, "attachments", householdID, "*"))
+		if len(files) != 1 {
+			t.Fatalf("round %d: %d files stored, want 1", round, len(files))
+		}
+
+		// Make room for the next round
+		os.Remove(files[0])
+		db.Delete(&uploaded[0])
+	}
+}
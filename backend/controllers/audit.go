@@ -0,0 +1,19 @@
+package controllers
+
+import (
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// recordAudit stores an audit event for the request's household
+func recordAudit(tx *gorm.DB, c *gin.Context, householdID, userID, action, detail string) error {
+	return tx.Create(&models.AuditEvent{
+		HouseholdID: householdID,
+		UserID:      userID,
+		Action:      action,
+		Detail:      detail,
+		IPAddress:   c.ClientIP(),
+	}).Error
+}
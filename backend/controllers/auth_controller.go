@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"household-todo-backend/models"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errRefreshTokenReused = errors.New("refresh token reused")
+	errLegacyTokenUsed    = errors.New("legacy token already exchanged")
+)
+
+type AuthController struct {
+	DB *gorm.DB
+}
+
+func NewAuthController(db *gorm.DB) *AuthController {
+	return &AuthController{DB: db}
+}
+
+type LegacyTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// authTokens is the token pair handed to a device when it signs in or refreshes
+type authTokens struct {
+	Token        string
+	RefreshToken string
+	ExpiresIn    int
+}
+
+// createSession starts a new session for a device and issues its first tokens
+func createSession(tx *gorm.DB, c *gin.Context, userID, householdID, deviceID string) (authTokens, error) {
+	now := time.Now()
+	session := models.Session{
+		UserID:      userID,
+		HouseholdID: householdID,
+		DeviceID:    deviceID,
+		UserAgent:   c.Request.UserAgent(),
+		IPAddress:   c.ClientIP(),
+		LastUsedAt:  &now,
+	}
+	if err := tx.Create(&session).Error; err != nil {
+		return authTokens{}, err
+	}
+	return issueTokens(tx, session)
+}
+
+// issueTokens creates a fresh refresh token in the session's family and signs
+// a matching access token
+func issueTokens(tx *gorm.DB, session models.Session) (authTokens, error) {
+	refreshToken := utils.GenerateSecureToken(32)
+	record := models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(utils.RefreshTokenTTL),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return authTokens{}, err
+	}
+
+	token, err := utils.GenerateJWT(session.UserID, session.HouseholdID, session.DeviceID, session.ID)
+	if err != nil {
+		return authTokens{}, err
+	}
+
+	return authTokens{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(utils.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// revokeSessions revokes every active session matching the query
+func revokeSessions(tx *gorm.DB, reason string, query interface{}, args ...interface{}) error {
+	return tx.Model(&models.Session{}).
+		Where(query, args...).
+		Where("revoked_at IS NULL").
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_reason": reason}).Error
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Presenting a refresh token that was already used revokes the whole
+// session, since either the client or an attacker holds a stolen copy.
+func (ac *AuthController) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var record models.RefreshToken
+	if err := ac.DB.Where("token_hash = ?", utils.HashToken(req.RefreshToken)).First(&record).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	var session models.Session
+	if err := ac.DB.Where("id = ?", record.SessionID).First(&session).Error; err != nil || session.RevokedAt != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return
+	}
+
+	if record.UsedAt != nil {
+		revokeSessions(ac.DB, "refresh token reuse", "id = ?", session.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return
+	}
+
+	if time.Now().After(record.ExpiresAt) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token expired"})
+		return
+	}
+
+	var tokens authTokens
+	err := ac.DB.Transaction(func(tx *gorm.DB) error {
+		// Mark the token used atomically so two concurrent refreshes cannot both succeed
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", record.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshTokenReused
+		}
+
+		if err := tx.Model(&session).Updates(map[string]interface{}{
+			"last_used_at": time.Now(),
+			"ip_address":   c.ClientIP(),
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = issueTokens(tx, session)
+		return err
+	})
+	if err == errRefreshTokenReused {
+		revokeSessions(ac.DB, "refresh token reuse", "id = ?", session.ID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":        tokens.Token,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+	})
+}
+
+// ExchangeLegacyToken signs in a device that still holds an access token
+// from before sessions existed. Such tokens carry no jti and are no longer
+// accepted by the auth middleware; each device can trade its token for a
+// session once, as long as it has never had one.
+func (ac *AuthController) ExchangeLegacyToken(c *gin.Context) {
+	var req LegacyTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims, err := utils.ValidateJWT(req.Token)
+	if err != nil || claims.ID != "" || claims.DeviceID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		return
+	}
+
+	var device models.Device
+	if err := ac.DB.Where("device_id = ? AND user_id = ?", claims.DeviceID, claims.UserID).First(&device).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Device has been removed"})
+		return
+	}
+	if !models.IsMember(ac.DB, claims.UserID, claims.HouseholdID) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "No longer a member of this household"})
+		return
+	}
+
+	var tokens authTokens
+	err = ac.DB.Transaction(func(tx *gorm.DB) error {
+		// Touching the device first locks the database for this exchange; a
+		// second exchange of the same token waits for it and then finds the
+		// session it created
+		now := time.Now()
+		if err := tx.Model(&device).Update("last_seen", now).Error; err != nil {
+			return err
+		}
+		var sessions int64
+		if err := tx.Model(&models.Session{}).Where("device_id = ?", device.DeviceID).Count(&sessions).Error; err != nil {
+			return err
+		}
+		if sessions > 0 {
+			return errLegacyTokenUsed
+		}
+
+		tokens, err = createSession(tx, c, claims.UserID, claims.HouseholdID, device.DeviceID)
+		return err
+	})
+	if err == errLegacyTokenUsed {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has already been exchanged"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":        tokens.Token,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+	})
+}
+
+// Logout revokes the session the access token belongs to
+func (ac *AuthController) Logout(c *gin.Context) {
+	sessionID := c.GetString("sessionID")
+
+	if err := revokeSessions(ac.DB, "logout", "id = ?", sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+// JWKS publishes the public keys that verify access tokens so other local
+// services can check them without sharing a secret
+func (ac *AuthController) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, utils.JWKS())
+}
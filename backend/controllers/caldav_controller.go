@@ -0,0 +1,885 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// caldavRoot is where the CalDAV tree is mounted
+	caldavRoot = "/caldav/"
+
+	// caldavSyncTokenPrefix starts every sync token and collection tag. The
+	// rest is the time of the last change in the collection.
+	caldavSyncTokenPrefix = "urn:household-todo:sync:"
+
+	// caldavMaxObjectSize caps uploaded calendar objects
+	caldavMaxObjectSize = 1 << 20
+
+	// caldavAllow lists the methods the CalDAV tree supports
+	caldavAllow = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, PROPPATCH, REPORT"
+)
+
+// Kinds of resource in the CalDAV tree
+const (
+	davRoot      = "root"
+	davPrincipal = "principal"
+	davHome      = "home"
+	davCalendar  = "calendar"
+	davObject    = "object"
+)
+
+// davTarget is a resolved request path. The tree is
+//
+//	/caldav/                               root
+//	/caldav/principals/<userId>/           the token owner
+//	/caldav/calendars/                     calendar home
+//	/caldav/calendars/<householdId>/       the household's tasks
+//	/caldav/calendars/<householdId>/<name> one task as a VTODO
+type davTarget struct {
+	kind string
+	name string
+	task *models.Task
+}
+
+type CalDAVController struct {
+	DB *gorm.DB
+}
+
+func NewCalDAVController(db *gorm.DB) *CalDAVController {
+	return &CalDAVController{DB: db}
+}
+
+// WellKnown sends service discovery to the CalDAV root (RFC 6764)
+func (cc *CalDAVController) WellKnown(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, caldavRoot)
+}
+
+// Options advertises CalDAV support. It does not need authentication.
+func (cc *CalDAVController) Options(c *gin.Context) {
+	c.Header("DAV", "1, 3, calendar-access")
+	c.Header("Allow", caldavAllow)
+	c.Status(http.StatusOK)
+}
+
+// Propfind returns properties of a resource and, with Depth: 1, its members
+func (cc *CalDAVController) Propfind(c *gin.Context) {
+	target, ok := cc.resolve(c)
+	if !ok {
+		return
+	}
+
+	body, err := readDAVBody(c)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Invalid XML body")
+		return
+	}
+	var names []xml.Name
+	if body != nil {
+		if prop := body.child(nsDAV, "prop"); prop != nil {
+			for _, child := range prop.Children {
+				names = append(names, child.XMLName)
+			}
+		}
+	}
+
+	responses := []davResponse{cc.propResponse(c, target, names)}
+	if c.GetHeader("Depth") != "0" {
+		members, err := cc.members(c, target)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to list resources")
+			return
+		}
+		for _, member := range members {
+			responses = append(responses, cc.propResponse(c, member, names))
+		}
+	}
+
+	writeMultistatus(c, responses, "")
+}
+
+// Proppatch accepts no changes; clients setting colours or order are told
+// the properties are read-only
+func (cc *CalDAVController) Proppatch(c *gin.Context) {
+	target, ok := cc.resolve(c)
+	if !ok {
+		return
+	}
+
+	body, err := readDAVBody(c)
+	if err != nil || body == nil {
+		c.String(http.StatusBadRequest, "Invalid XML body")
+		return
+	}
+
+	var b strings.Builder
+	for _, update := range body.Children {
+		if prop := update.child(nsDAV, "prop"); prop != nil {
+			for _, child := range prop.Children {
+				b.WriteString(davTag(child.XMLName, ""))
+			}
+		}
+	}
+	var out strings.Builder
+	writePropstat(&out, b.String(), http.StatusForbidden)
+
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", []byte(
+		`<?xml version="1.0" encoding="utf-8"?>`+"\n"+
+			`<d:multistatus xmlns:d="DAV:"><d:response>`+davHref(cc.href(c, target))+out.String()+`</d:response></d:multistatus>`))
+}
+
+// Report answers calendar-query, calendar-multiget and sync-collection
+// reports on the household calendar
+func (cc *CalDAVController) Report(c *gin.Context) {
+	target, ok := cc.resolve(c)
+	if !ok {
+		return
+	}
+
+	body, err := readDAVBody(c)
+	if err != nil || body == nil {
+		c.String(http.StatusBadRequest, "Invalid XML body")
+		return
+	}
+	if target.kind != davCalendar && !(target.kind == davObject && body.XMLName.Local != "sync-collection") {
+		writeDAVError(c, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "supported-report"})
+		return
+	}
+
+	var names []xml.Name
+	if prop := body.child(nsDAV, "prop"); prop != nil {
+		for _, child := range prop.Children {
+			names = append(names, child.XMLName)
+		}
+	}
+
+	householdID := c.GetString("householdID")
+	switch {
+	case body.XMLName.Space == nsCalDAV && body.XMLName.Local == "calendar-query":
+		tasks, err := cc.loadTasks(cc.DB.Where("household_id = ?", householdID))
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to fetch tasks")
+			return
+		}
+		if target.kind == davObject {
+			tasks = []models.Task{*target.task}
+		}
+		var responses []davResponse
+		for i := range tasks {
+			if matchesCalendarFilter(body.child(nsCalDAV, "filter"), &tasks[i]) {
+				responses = append(responses, cc.propResponse(c, davTarget{kind: davObject, task: &tasks[i]}, names))
+			}
+		}
+		writeMultistatus(c, responses, "")
+
+	case body.XMLName.Space == nsCalDAV && body.XMLName.Local == "calendar-multiget":
+		var responses []davResponse
+		for _, href := range body.children(nsDAV, "href") {
+			member, err := cc.resolvePath(c, hrefPath(href.Text))
+			if err != nil || member.kind != davObject {
+				responses = append(responses, davResponse{href: strings.TrimSpace(href.Text), status: http.StatusNotFound})
+				continue
+			}
+			responses = append(responses, cc.propResponse(c, member, names))
+		}
+		writeMultistatus(c, responses, "")
+
+	case body.XMLName.Space == nsDAV && body.XMLName.Local == "sync-collection":
+		cc.syncCollection(c, body, names)
+
+	default:
+		writeDAVError(c, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "supported-report"})
+	}
+}
+
+// syncCollection reports the tasks changed and deleted since the client's
+// sync token, or every task for an initial sync (RFC 6578)
+func (cc *CalDAVController) syncCollection(c *gin.Context, body *davElement, names []xml.Name) {
+	householdID := c.GetString("householdID")
+	settings := models.GetHouseholdSettings(cc.DB, householdID)
+	revision := cc.revision(householdID, settings)
+
+	query := cc.DB.Where("household_id = ?", householdID)
+	var tombstones []models.TaskTombstone
+
+	token := ""
+	if element := body.child(nsDAV, "sync-token"); element != nil {
+		token = strings.TrimSpace(element.Text)
+	}
+	if token != "" {
+		since, err := parseSyncToken(token)
+		if err != nil || since.After(revision) {
+			writeDAVError(c, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "valid-sync-token"})
+			return
+		}
+		// A time zone change alters every task's times, so resend them all
+		if !settings.UpdatedAt.After(since) {
+			query = query.Where("updated_at > ?", since)
+		}
+		if err := cc.DB.Where("household_id = ? AND deleted_at > ?", householdID, since).Find(&tombstones).Error; err != nil {
+			c.String(http.StatusInternalServerError, "Failed to fetch changes")
+			return
+		}
+	}
+
+	tasks, err := cc.loadTasks(query)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to fetch changes")
+		return
+	}
+
+	var responses []davResponse
+	for i := range tasks {
+		responses = append(responses, cc.propResponse(c, davTarget{kind: davObject, task: &tasks[i]}, names))
+	}
+	for _, tombstone := range tombstones {
+		responses = append(responses, davResponse{
+			href:   cc.calendarHref(householdID) + url.PathEscape(tombstone.CalendarName),
+			status: http.StatusNotFound,
+		})
+	}
+
+	writeMultistatus(c, responses, syncToken(revision))
+}
+
+// Get returns a task as a calendar object
+func (cc *CalDAVController) Get(c *gin.Context) {
+	target, ok := cc.resolve(c)
+	if !ok {
+		return
+	}
+	if target.kind != davObject {
+		c.Header("Allow", "OPTIONS, PROPFIND, REPORT")
+		c.String(http.StatusMethodNotAllowed, "Collections cannot be downloaded; use PROPFIND or REPORT")
+		return
+	}
+
+	body, etag := cc.object(target.task)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", target.task.UpdatedAt.UTC().Format(http.TimeFormat))
+	if match := c.GetHeader("If-None-Match"); match != "" && strings.Contains(match, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(body))
+}
+
+// Put creates or replaces a task from a VTODO. Summary, description, due
+// date, completion, category, priority and recurrence are taken from the
+// object; attendees are ignored and assignments stay as they are.
+func (cc *CalDAVController) Put(c *gin.Context) {
+	target, ok := cc.resolve(c)
+	if !ok {
+		return
+	}
+	if target.kind != davObject {
+		c.String(http.StatusMethodNotAllowed, "Only calendar objects can be written")
+		return
+	}
+
+	// New objects resolve with a name and no task
+	exists := target.task != nil
+	etag := ""
+	if exists {
+		_, etag = cc.object(target.task)
+	}
+	if !etagPreconditionsMet(c, exists, etag) {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, caldavMaxObjectSize+1))
+	if err != nil || len(data) > caldavMaxObjectSize {
+		c.String(http.StatusRequestEntityTooLarge, "Calendar object is too large")
+		return
+	}
+	calendar, err := utils.ParseICal(string(data))
+	if err != nil {
+		writeDAVError(c, http.StatusBadRequest, xml.Name{Space: nsCalDAV, Local: "valid-calendar-data"})
+		return
+	}
+	todo := masterTodo(calendar)
+	if todo == nil {
+		writeDAVError(c, http.StatusForbidden, xml.Name{Space: nsCalDAV, Local: "supported-calendar-component"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+	settings := models.GetHouseholdSettings(cc.DB, householdID)
+
+	task := models.Task{HouseholdID: householdID, CreatorID: userID, Category: settings.DefaultCategory}
+	if exists {
+		task = *target.task
+	} else {
+		task.CalendarName = target.name
+		if uid := todo.Property("UID"); uid != nil && uid.Value != "" {
+			task.CalendarUID = uid.Text()
+			if cc.uidInUse(householdID, task.CalendarUID) {
+				writeDAVError(c, http.StatusForbidden, xml.Name{Space: nsCalDAV, Local: "no-uid-conflict"})
+				return
+			}
+		}
+	}
+	wasCompleted := task.Completed
+
+	if err := applyTodo(todo, &task, settings, userID); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	completing := task.Completed && !wasCompleted
+
+	// Tasks that need checking are submitted to their reviewer instead
+	submitting := false
+	if completing && task.RequiresVerification {
+		if canReviewTask(cc.DB, &task, userID) {
+			signOff(&task, userID, time.Now())
+		} else {
+			task.Completed = false
+			task.CompletedAt = nil
+			task.CompletedBy = nil
+			completing = false
+			submitting = task.ReviewStatus != models.ReviewPending
+		}
+	}
+	if !task.Completed && wasCompleted {
+		clearReview(&task)
+	}
+
+	if (completing || submitting) && exists && settings.EnforceDependencies {
+		blockers, err := models.OpenBlockers(cc.DB, task.ID)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to check dependencies")
+			return
+		}
+		if len(blockers) > 0 {
+			c.String(http.StatusConflict, "Task is waiting on tasks that are not done")
+			return
+		}
+	}
+
+	err = cc.DB.Transaction(func(tx *gorm.DB) error {
+		if submitting {
+			return submitForReview(tx, &task, userID)
+		}
+
+		if !exists {
+			// A task uploaded as done is created open and then completed, so
+			// it goes through the same steps as any other completion
+			task.Completed = false
+			if err := tx.Create(&task).Error; err != nil {
+				return err
+			}
+			task.Completed = completing
+		}
+
+		// Of two concurrent uploads only one gets to credit or reverse points
+		switch {
+		case completing:
+			return saveCompletion(tx, &task, userID)
+		case wasCompleted && !task.Completed:
+			return saveReopening(tx, &task)
+		case exists:
+			return tx.Save(&task).Error
+		}
+		return nil
+	})
+	if err == errCompletionChanged {
+		c.String(http.StatusConflict, "Task was changed by someone else")
+		return
+	}
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Failed to save task")
+		return
+	}
+
+	// The stored object differs from the upload (IDs, normalized rules), so
+	// no ETag is returned and clients fetch it again
+	if exists {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.Header("Location", cc.href(c, davTarget{kind: davObject, task: &task}))
+	c.Status(http.StatusCreated)
+}
+
+// Delete removes a task
+func (cc *CalDAVController) Delete(c *gin.Context) {
+	target, ok := cc.resolve(c)
+	if !ok {
+		return
+	}
+	if target.kind != davObject {
+		c.String(http.StatusForbidden, "Collections cannot be deleted")
+		return
+	}
+	if target.task == nil {
+		c.String(http.StatusNotFound, "Not found")
+		return
+	}
+
+	_, etag := cc.object(target.task)
+	if !etagPreconditionsMet(c, true, etag) {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+
+	var files []string
+	if err := cc.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		files, err = deleteTask(tx, target.task, c.GetString("userID"))
+		return err
+	}); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to delete task")
+		return
+	}
+	removeFiles(files)
+	c.Status(http.StatusNoContent)
+}
+
+// resolve looks up the request path, writing a 404 if it does not exist.
+// Objects that do not exist yet resolve for PUT only.
+func (cc *CalDAVController) resolve(c *gin.Context) (davTarget, bool) {
+	target, err := cc.resolvePath(c, c.Param("path"))
+	if errors.Is(err, gorm.ErrRecordNotFound) && c.Request.Method == http.MethodPut && target.kind == davObject {
+		return target, true
+	}
+	if err != nil {
+		c.String(http.StatusNotFound, "Not found")
+		return target, false
+	}
+	return target, true
+}
+
+// resolvePath maps a path below /caldav to a resource. Only the token's
+// user and household are visible.
+func (cc *CalDAVController) resolvePath(c *gin.Context, path string) (davTarget, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "":
+		return davTarget{kind: davRoot}, nil
+	case len(parts) == 2 && parts[0] == "principals" && parts[1] == userID:
+		return davTarget{kind: davPrincipal}, nil
+	case len(parts) == 1 && parts[0] == "calendars":
+		return davTarget{kind: davHome}, nil
+	case len(parts) == 2 && parts[0] == "calendars" && parts[1] == householdID:
+		return davTarget{kind: davCalendar}, nil
+	case len(parts) == 3 && parts[0] == "calendars" && parts[1] == householdID && parts[2] != "":
+		target := davTarget{kind: davObject, name: parts[2]}
+		tasks, err := cc.loadTasks(cc.DB.
+			Where("household_id = ?", householdID).
+			Where("calendar_name = ? OR (calendar_name = '' AND id = ?)", parts[2], strings.TrimSuffix(parts[2], ".ics")))
+		if err != nil {
+			return target, err
+		}
+		if len(tasks) == 0 {
+			return target, gorm.ErrRecordNotFound
+		}
+		target.task = &tasks[0]
+		return target, nil
+	}
+	return davTarget{}, gorm.ErrRecordNotFound
+}
+
+// members lists the resources inside a collection
+func (cc *CalDAVController) members(c *gin.Context, target davTarget) ([]davTarget, error) {
+	switch target.kind {
+	case davRoot:
+		return []davTarget{{kind: davPrincipal}, {kind: davHome}}, nil
+	case davHome:
+		return []davTarget{{kind: davCalendar}}, nil
+	case davCalendar:
+		tasks, err := cc.loadTasks(cc.DB.Where("household_id = ?", c.GetString("householdID")))
+		if err != nil {
+			return nil, err
+		}
+		members := make([]davTarget, len(tasks))
+		for i := range tasks {
+			members[i] = davTarget{kind: davObject, task: &tasks[i]}
+		}
+		return members, nil
+	}
+	return nil, nil
+}
+
+// loadTasks fetches tasks with the relationships calendar objects need
+func (cc *CalDAVController) loadTasks(query *gorm.DB) ([]models.Task, error) {
+	var tasks []models.Task
+	err := query.Preload("Creator").Preload("Assignments.User").Order("created_at").Find(&tasks).Error
+	return tasks, err
+}
+
+// object renders a task and returns it with its ETag
+func (cc *CalDAVController) object(task *models.Task) (string, string) {
+	body := taskObject(*task, models.GetHouseholdSettings(cc.DB, task.HouseholdID))
+	sum := sha256.Sum256([]byte(body))
+	return body, `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// revision is the time of the last change to the household's tasks
+func (cc *CalDAVController) revision(householdID string, settings models.HouseholdSettings) time.Time {
+	latest := settings.UpdatedAt
+
+	var tasks []models.Task
+	cc.DB.Select("updated_at").Where("household_id = ?", householdID).Order("updated_at DESC").Limit(1).Find(&tasks)
+	if len(tasks) > 0 && tasks[0].UpdatedAt.After(latest) {
+		latest = tasks[0].UpdatedAt
+	}
+
+	var tombstones []models.TaskTombstone
+	cc.DB.Select("deleted_at").Where("household_id = ?", householdID).Order("deleted_at DESC").Limit(1).Find(&tombstones)
+	if len(tombstones) > 0 && tombstones[0].DeletedAt.After(latest) {
+		latest = tombstones[0].DeletedAt
+	}
+	return latest
+}
+
+// uidInUse reports whether another task in the household has the UID
+func (cc *CalDAVController) uidInUse(householdID, uid string) bool {
+	var count int64
+	cc.DB.Model(&models.Task{}).
+		Where("household_id = ?", householdID).
+		Where("calendar_uid = ? OR (calendar_uid = '' AND id = ?)", uid, strings.TrimSuffix(uid, "@household-todo")).
+		Count(&count)
+	return count > 0
+}
+
+func (cc *CalDAVController) principalHref(c *gin.Context) string {
+	return caldavRoot + "principals/" + url.PathEscape(c.GetString("userID")) + "/"
+}
+
+func (cc *CalDAVController) calendarHref(householdID string) string {
+	return caldavRoot + "calendars/" + url.PathEscape(householdID) + "/"
+}
+
+func (cc *CalDAVController) href(c *gin.Context, target davTarget) string {
+	switch target.kind {
+	case davPrincipal:
+		return cc.principalHref(c)
+	case davHome:
+		return caldavRoot + "calendars/"
+	case davCalendar:
+		return cc.calendarHref(c.GetString("householdID"))
+	case davObject:
+		name := target.name
+		if target.task != nil {
+			name = target.task.CalendarResourceName()
+		}
+		return cc.calendarHref(c.GetString("householdID")) + url.PathEscape(name)
+	}
+	return caldavRoot
+}
+
+// propResponse builds the response for one resource. Without requested
+// names it returns every property the resource has (allprop), except
+// calendar data.
+func (cc *CalDAVController) propResponse(c *gin.Context, target davTarget, names []xml.Name) davResponse {
+	response := davResponse{href: cc.href(c, target)}
+	props := cc.properties(c, target)
+
+	if len(names) == 0 {
+		for _, prop := range props {
+			if prop.name.Local != "calendar-data" {
+				response.found = append(response.found, prop)
+			}
+		}
+		return response
+	}
+
+	for _, name := range names {
+		found := false
+		for _, prop := range props {
+			if prop.name == name {
+				response.found = append(response.found, prop)
+				found = true
+				break
+			}
+		}
+		if !found {
+			response.missing = append(response.missing, name)
+		}
+	}
+	return response
+}
+
+// properties returns every property of a resource
+func (cc *CalDAVController) properties(c *gin.Context, target davTarget) []davProp {
+	dav := func(local string) xml.Name { return xml.Name{Space: nsDAV, Local: local} }
+	cal := func(local string) xml.Name { return xml.Name{Space: nsCalDAV, Local: local} }
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	collection := davTag(dav("collection"), "")
+	props := []davProp{
+		{dav("current-user-principal"), davHref(cc.principalHref(c))},
+		{dav("current-user-privilege-set"), davPrivileges(c, target.kind)},
+	}
+
+	switch target.kind {
+	case davRoot:
+		props = append(props,
+			davProp{dav("resourcetype"), collection},
+			davProp{dav("displayname"), "Household Todo"},
+		)
+
+	case davPrincipal:
+		var user models.User
+		cc.DB.Where("id = ?", userID).First(&user)
+		props = append(props,
+			davProp{dav("resourcetype"), collection + davTag(dav("principal"), "")},
+			davProp{dav("displayname"), davText(user.Name)},
+			davProp{dav("principal-URL"), davHref(cc.principalHref(c))},
+			davProp{cal("calendar-home-set"), davHref(caldavRoot + "calendars/")},
+			davProp{cal("calendar-user-address-set"), davHref("urn:uuid:" + userID)},
+		)
+
+	case davHome:
+		props = append(props,
+			davProp{dav("resourcetype"), collection},
+			davProp{dav("displayname"), "Calendars"},
+		)
+
+	case davCalendar:
+		var household models.Household
+		cc.DB.Where("id = ?", householdID).First(&household)
+		settings := models.GetHouseholdSettings(cc.DB, householdID)
+		token := syncToken(cc.revision(householdID, settings))
+
+		props = append(props,
+			davProp{dav("resourcetype"), collection + davTag(cal("calendar"), "")},
+			davProp{dav("displayname"), davText(household.Name)},
+			davProp{dav("owner"), davHref(cc.principalHref(c))},
+			davProp{dav("sync-token"), davText(token)},
+			davProp{xml.Name{Space: nsCalendarServer, Local: "getctag"}, davText(token)},
+			davProp{dav("supported-report-set"), davSupportedReports()},
+			davProp{cal("calendar-description"), davText("Tasks in " + household.Name)},
+			davProp{cal("supported-calendar-component-set"), `<c:comp name="VTODO"/>`},
+			davProp{cal("supported-calendar-data"), `<c:calendar-data content-type="text/calendar" version="2.0"/>`},
+			davProp{cal("max-resource-size"), strconv.Itoa(caldavMaxObjectSize)},
+		)
+		if settings.Timezone != models.DefaultTimezone {
+			var w utils.ICalBuilder
+			w.Begin("VCALENDAR")
+			w.Property("VERSION", "2.0")
+			w.Text("PRODID", calendarProductID)
+			writeCalendarTimezone(&w, nil, settings)
+			w.End("VCALENDAR")
+			props = append(props, davProp{cal("calendar-timezone"), davText(w.String())})
+		}
+
+	case davObject:
+		body, etag := cc.object(target.task)
+		props = append(props,
+			davProp{dav("resourcetype"), ""},
+			davProp{dav("displayname"), davText(target.task.Title)},
+			davProp{dav("getetag"), davText(etag)},
+			davProp{dav("getcontenttype"), "text/calendar; charset=utf-8; component=VTODO"},
+			davProp{dav("getcontentlength"), strconv.Itoa(len(body))},
+			davProp{dav("getlastmodified"), target.task.UpdatedAt.UTC().Format(http.TimeFormat)},
+			davProp{cal("calendar-data"), davText(body)},
+		)
+	}
+	return props
+}
+
+// davPrivileges lists what the token may do; writing needs tasks:write
+func davPrivileges(c *gin.Context, kind string) string {
+	privileges := []string{"read"}
+	if kind == davCalendar || kind == davObject {
+		for _, scope := range c.GetStringSlice("tokenScopes") {
+			if scope == models.ScopeTasksWrite {
+				privileges = append(privileges, "write", "write-content", "bind", "unbind")
+			}
+		}
+	}
+	var b strings.Builder
+	for _, privilege := range privileges {
+		b.WriteString("<d:privilege><d:" + privilege + "/></d:privilege>")
+	}
+	return b.String()
+}
+
+func davSupportedReports() string {
+	var b strings.Builder
+	for _, report := range []string{"<c:calendar-query/>", "<c:calendar-multiget/>", "<d:sync-collection/>"} {
+		b.WriteString("<d:supported-report><d:report>" + report + "</d:report></d:supported-report>")
+	}
+	return b.String()
+}
+
+func syncToken(revision time.Time) string {
+	return caldavSyncTokenPrefix + strconv.FormatInt(revision.UnixNano(), 10)
+}
+
+func parseSyncToken(token string) (time.Time, error) {
+	if !strings.HasPrefix(token, caldavSyncTokenPrefix) {
+		return time.Time{}, errors.New("invalid sync token")
+	}
+	nanos, err := strconv.ParseInt(strings.TrimPrefix(token, caldavSyncTokenPrefix), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, nanos), nil
+}
+
+// hrefPath returns the path below /caldav of an href, which may be an
+// absolute URL and is percent-encoded
+func hrefPath(href string) string {
+	parsed, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(parsed.Path, strings.TrimSuffix(caldavRoot, "/"))
+}
+
+// etagPreconditionsMet checks If-Match and If-None-Match against the
+// current ETag of a resource
+func etagPreconditionsMet(c *gin.Context, exists bool, etag string) bool {
+	if match := c.GetHeader("If-Match"); match != "" {
+		if !exists || (match != "*" && !strings.Contains(match, etag)) {
+			return false
+		}
+	}
+	if noneMatch := c.GetHeader("If-None-Match"); noneMatch != "" && exists {
+		if noneMatch == "*" || strings.Contains(noneMatch, etag) {
+			return false
+		}
+	}
+	return true
+}
+
+// masterTodo returns the VTODO of a calendar object, skipping overrides of
+// single occurrences, which tasks cannot represent
+func masterTodo(calendar *utils.ICalComponent) *utils.ICalComponent {
+	for i := range calendar.Components {
+		component := &calendar.Components[i]
+		if component.Name == calendarTodo && component.Property("RECURRENCE-ID") == nil {
+			return component
+		}
+	}
+	return nil
+}
+
+// applyTodo copies a VTODO's fields onto a task
+func applyTodo(todo *utils.ICalComponent, task *models.Task, settings models.HouseholdSettings, userID string) error {
+	task.Title = "Untitled task"
+	if summary := todo.Property("SUMMARY"); summary != nil && strings.TrimSpace(summary.Text()) != "" {
+		task.Title = strings.TrimSpace(summary.Text())
+	}
+
+	task.Description = ""
+	if description := todo.Property("DESCRIPTION"); description != nil {
+		task.Description = description.Text()
+	}
+
+	if categories := todo.Property("CATEGORIES"); categories != nil {
+		for _, value := range strings.Split(categories.Value, ",") {
+			category := models.TaskCategory(strings.ToUpper(strings.TrimSpace(utils.ICalUnescape(value))))
+			if category.Valid() {
+				task.Category = category
+				break
+			}
+		}
+	}
+
+	if priority := todo.Property("PRIORITY"); priority != nil {
+		if value, err := strconv.Atoi(strings.TrimSpace(priority.Value)); err == nil {
+			task.Priority = taskPriority(value)
+		}
+	}
+
+	task.DueDate = nil
+	due := todo.Property("DUE")
+	if due == nil {
+		due = todo.Property("DTSTART")
+	}
+	if due != nil {
+		t, _, err := utils.ParseICalTime(due, settings.Location())
+		if err != nil {
+			return errors.New("invalid " + due.Name)
+		}
+		task.DueDate = &t
+	}
+
+	recurrence := ""
+	if rule := todo.Property("RRULE"); rule != nil {
+		recurrence = rule.Value
+	}
+	normalized, err := normalizeRecurrence(recurrence, task.DueDate, settings)
+	if err != nil {
+		return err
+	}
+	task.Recurrence = normalized
+
+	status := todo.Property("STATUS")
+	completedAt := todo.Property("COMPLETED")
+	completed := (status != nil && strings.EqualFold(status.Value, "COMPLETED")) || completedAt != nil
+	switch {
+	case completed && !task.Completed:
+		now := time.Now()
+		if completedAt != nil {
+			if t, _, err := utils.ParseICalTime(completedAt, time.UTC); err == nil {
+				now = t
+			}
+		}
+		task.Completed = true
+		task.CompletedAt = &now
+		task.CompletedBy = &userID
+	case !completed && task.Completed:
+		task.Completed = false
+		task.CompletedAt = nil
+		task.CompletedBy = nil
+	}
+	return nil
+}
+
+// matchesCalendarFilter applies the parts of a calendar-query filter tasks
+// can be matched against: the component name, a time range on the due date
+// and whether COMPLETED is defined. Other conditions match everything,
+// which clients tolerate.
+func matchesCalendarFilter(filter *davElement, task *models.Task) bool {
+	if filter == nil {
+		return true
+	}
+	calendar := filter.child(nsCalDAV, "comp-filter")
+	if calendar == nil || calendar.attr("name") != "VCALENDAR" {
+		return true
+	}
+	for _, component := range calendar.children(nsCalDAV, "comp-filter") {
+		if component.attr("name") != calendarTodo {
+			return component.child(nsCalDAV, "is-not-defined") != nil
+		}
+		if timeRange := component.child(nsCalDAV, "time-range"); timeRange != nil && task.DueDate != nil {
+			if start, err := time.Parse("20060102T150405Z", timeRange.attr("start")); err == nil && task.DueDate.Before(start) {
+				return false
+			}
+			if end, err := time.Parse("20060102T150405Z", timeRange.attr("end")); err == nil && !task.DueDate.Before(end) {
+				return false
+			}
+		}
+		for _, propFilter := range component.children(nsCalDAV, "prop-filter") {
+			if propFilter.attr("name") == "COMPLETED" && propFilter.child(nsCalDAV, "is-not-defined") != nil && task.Completed {
+				return false
+			}
+		}
+	}
+	return true
+}
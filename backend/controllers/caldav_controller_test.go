@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCalDAVPutCompletionStopsTimers(t *testing.T) {
+	db := newTestDB(t)
+	householdID, userID := newTestHousehold(t, db)
+
+	task := models.Task{Title: "Mow the lawn", Points: 5, CreatorID: userID, HouseholdID: householdID}
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+	timer := models.TimeEntry{TaskID: task.ID, HouseholdID: householdID, UserID: userID, TaskTitle: task.Title, StartedAt: time.Now().Add(-time.Hour)}
+	if err := db.Create(&timer).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/caldav/*path", authenticated(userID, householdID), NewCalDAVController(db).Put)
+
+	todo := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VTODO",
+		"UID:" + task.ID,
+		"SUMMARY:Mow the lawn",
+		"STATUS:COMPLETED",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+	w := serve(r, http.MethodPut, "/caldav/calendars/"+householdID+"/"+task.ID+".ics", "text/calendar", todo)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204 (%s)", w.Code, w.Body)
+	}
+
+	if err := db.First(&task, "id = ?", task.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !task.Completed {
+		t.Fatal("task was not completed")
+	}
+	if err := db.First(&timer, "id = ?", timer.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if timer.EndedAt == nil || timer.Seconds < 3599 {
+		t.Errorf("timer = ended %v after %ds, want stopped after an hour", timer.EndedAt, timer.Seconds)
+	}
+
+	var credits int64
+	db.Model(&models.PointsEntry{}).Where("task_id = ? AND kind = ?", task.ID, models.PointsTaskCompleted).Count(&credits)
+	if credits != 1 {
+		t.Errorf("completion credited %d times, want once", credits)
+	}
+}
+
+func TestCalDAVPutNewCompletedTask(t *testing.T) {
+	db := newTestDB(t)
+	householdID, userID := newTestHousehold(t, db)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/caldav/*path", authenticated(userID, householdID), NewCalDAVController(db).Put)
+
+	todo := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTODO",
+		"UID:client-uid-1",
+		"SUMMARY:Water plants",
+		"DUE;VALUE=DATE:20261014",
+		"RRULE:FREQ=WEEKLY",
+		"STATUS:COMPLETED",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+	w := serve(r, http.MethodPut, "/caldav/calendars/"+householdID+"/client.ics", "text/calendar", todo)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201 (%s)", w.Code, w.Body)
+	}
+
+	var tasks []models.Task
+	if err := db.Where("household_id = ?", householdID).Order("created_at").Find(&tasks).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("got %d tasks, want the upload and its next occurrence", len(tasks))
+	}
+	if !tasks[0].Completed || tasks[0].CalendarName != "client.ics" || tasks[0].Recurrence != "" {
+		t.Errorf("uploaded task = completed %v, name %q, recurrence %q", tasks[0].Completed, tasks[0].CalendarName, tasks[0].Recurrence)
+	}
+	if tasks[1].Completed || tasks[1].Recurrence != "FREQ=WEEKLY" {
+		t.Errorf("next occurrence = completed %v, recurrence %q", tasks[1].Completed, tasks[1].Recurrence)
+	}
+}
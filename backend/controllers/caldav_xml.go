@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// XML namespaces used by WebDAV, CalDAV and the calendar server extensions
+// Apple and DAVx5 rely on
+const (
+	nsDAV            = "DAV:"
+	nsCalDAV         = "urn:ietf:params:xml:ns:caldav"
+	nsCalendarServer = "http://calendarserver.org/ns/"
+	nsAppleICal      = "http://apple.com/ns/ical/"
+)
+
+// davPrefixes are the prefixes multistatus responses declare up front
+var davPrefixes = []struct{ prefix, ns string }{
+	{"d", nsDAV},
+	{"c", nsCalDAV},
+	{"cs", nsCalendarServer},
+	{"ical", nsAppleICal},
+}
+
+// davElement is a generic XML element from a request body
+type davElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr   `xml:",any,attr"`
+	Children []davElement `xml:",any"`
+	Text     string       `xml:",chardata"`
+}
+
+// child returns the first child element with the given name, or nil
+func (e *davElement) child(ns, local string) *davElement {
+	for i := range e.Children {
+		if e.Children[i].XMLName.Space == ns && e.Children[i].XMLName.Local == local {
+			return &e.Children[i]
+		}
+	}
+	return nil
+}
+
+// children returns every child element with the given name
+func (e *davElement) children(ns, local string) []davElement {
+	var found []davElement
+	for _, child := range e.Children {
+		if child.XMLName.Space == ns && child.XMLName.Local == local {
+			found = append(found, child)
+		}
+	}
+	return found
+}
+
+// attr returns the value of an unqualified attribute
+func (e *davElement) attr(name string) string {
+	for _, attr := range e.Attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// readDAVBody parses a request body. An empty body yields a nil element.
+func readDAVBody(c *gin.Context) (*davElement, error) {
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	var root davElement
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+// davProp is a property value as raw XML content
+type davProp struct {
+	name  xml.Name
+	value string
+}
+
+// davResponse is one response in a multistatus body. A response with a
+// status and no properties reports the resource itself, e.g. as deleted.
+type davResponse struct {
+	href    string
+	status  int
+	found   []davProp
+	missing []xml.Name
+}
+
+// writeMultistatus sends a 207 Multi-Status response
+func writeMultistatus(c *gin.Context, responses []davResponse, syncToken string) {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	b.WriteString("<d:multistatus")
+	for _, p := range davPrefixes {
+		fmt.Fprintf(&b, ` xmlns:%s="%s"`, p.prefix, p.ns)
+	}
+	b.WriteString(">")
+
+	for _, response := range responses {
+		b.WriteString("<d:response>")
+		b.WriteString(davTag(xml.Name{Space: nsDAV, Local: "href"}, davText(response.href)))
+		if response.status != 0 {
+			b.WriteString(davTag(xml.Name{Space: nsDAV, Local: "status"}, davStatus(response.status)))
+		}
+		if len(response.found) > 0 {
+			var props strings.Builder
+			for _, prop := range response.found {
+				props.WriteString(davTag(prop.name, prop.value))
+			}
+			writePropstat(&b, props.String(), http.StatusOK)
+		}
+		if len(response.missing) > 0 {
+			var props strings.Builder
+			for _, name := range response.missing {
+				props.WriteString(davTag(name, ""))
+			}
+			writePropstat(&b, props.String(), http.StatusNotFound)
+		}
+		b.WriteString("</d:response>")
+	}
+
+	if syncToken != "" {
+		b.WriteString(davTag(xml.Name{Space: nsDAV, Local: "sync-token"}, davText(syncToken)))
+	}
+	b.WriteString("</d:multistatus>")
+
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", []byte(b.String()))
+}
+
+func writePropstat(b *strings.Builder, props string, status int) {
+	b.WriteString("<d:propstat>")
+	b.WriteString(davTag(xml.Name{Space: nsDAV, Local: "prop"}, props))
+	b.WriteString(davTag(xml.Name{Space: nsDAV, Local: "status"}, davStatus(status)))
+	b.WriteString("</d:propstat>")
+}
+
+// writeDAVError sends a precondition or postcondition failure such as
+// DAV:valid-sync-token
+func writeDAVError(c *gin.Context, status int, condition xml.Name) {
+	body := `<?xml version="1.0" encoding="utf-8"?>` + "\n" +
+		`<d:error xmlns:d="DAV:" xmlns:c="` + nsCalDAV + `">` + davTag(condition, "") + `</d:error>`
+	c.Data(status, "application/xml; charset=utf-8", []byte(body))
+}
+
+// davTag renders an element, using the declared prefix for well-known
+// namespaces and an inline declaration for anything else
+func davTag(name xml.Name, inner string) string {
+	tag := ""
+	for _, p := range davPrefixes {
+		if p.ns == name.Space {
+			tag = p.prefix + ":" + name.Local
+		}
+	}
+	open := tag
+	if tag == "" {
+		tag = "x:" + name.Local
+		open = tag + ` xmlns:x="` + davText(name.Space) + `"`
+	}
+	if inner == "" {
+		return "<" + open + "/>"
+	}
+	return "<" + open + ">" + inner + "</" + tag + ">"
+}
+
+// davText escapes character data
+func davText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// davHref renders an href element
+func davHref(href string) string {
+	return davTag(xml.Name{Space: nsDAV, Local: "href"}, davText(href))
+}
+
+func davStatus(status int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", status, http.StatusText(status))
+}
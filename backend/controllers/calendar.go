@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"household-todo-backend/models"
+	"household-todo-backend/utils"
+)
+
+// Components tasks can be written as. VTODO keeps completion state natively;
+// VEVENT is for calendar apps that ignore to-dos.
+const (
+	calendarTodo  = "VTODO"
+	calendarEvent = "VEVENT"
+)
+
+// calendarProductID identifies this backend in generated calendars
+const calendarProductID = "-//Household Todo//Tasks//EN"
+
+// timedEventDuration is the length given to events for tasks due at a time
+const timedEventDuration = "PT30M"
+
+// taskUID returns the stable iCalendar UID of a task, so calendar apps
+// update an existing entry when the same task is exported again. Tasks
+// created over CalDAV keep the UID their client chose.
+func taskUID(task models.Task) string {
+	if task.CalendarUID != "" {
+		return task.CalendarUID
+	}
+	return task.ID + "@household-todo"
+}
+
+// taskCalendar renders the tasks that have a due date as an iCalendar
+// document in the household's time zone. Tasks need Creator and
+// Assignments.User preloaded.
+func taskCalendar(name string, tasks []models.Task, settings models.HouseholdSettings, component string) string {
+	var w utils.ICalBuilder
+	w.Begin("VCALENDAR")
+	w.Property("VERSION", "2.0")
+	w.Text("PRODID", calendarProductID)
+	w.Property("CALSCALE", "GREGORIAN")
+	w.Text("X-WR-CALNAME", name)
+	w.Text("X-WR-TIMEZONE", settings.Timezone)
+	writeCalendarTimezone(&w, tasks, settings)
+
+	for _, task := range tasks {
+		if task.DueDate != nil {
+			writeTaskComponent(&w, task, settings, component)
+		}
+	}
+	w.End("VCALENDAR")
+	return w.String()
+}
+
+// taskObject renders a single task as a calendar object resource for
+// CalDAV clients. Unlike taskCalendar it includes tasks without a due date.
+func taskObject(task models.Task, settings models.HouseholdSettings) string {
+	var w utils.ICalBuilder
+	w.Begin("VCALENDAR")
+	w.Property("VERSION", "2.0")
+	w.Text("PRODID", calendarProductID)
+	w.Property("CALSCALE", "GREGORIAN")
+	writeCalendarTimezone(&w, []models.Task{task}, settings)
+	writeTaskComponent(&w, task, settings, calendarTodo)
+	w.End("VCALENDAR")
+	return w.String()
+}
+
+// writeCalendarTimezone writes the household's VTIMEZONE unless it uses UTC
+func writeCalendarTimezone(w *utils.ICalBuilder, tasks []models.Task, settings models.HouseholdSettings) {
+	if settings.Timezone != models.DefaultTimezone {
+		// Cover the dated tasks and the years clients usually display. Whole
+		// years keep the output stable so feeds can be cached by ETag.
+		year := time.Now().In(settings.Location()).Year()
+		from := time.Date(year-1, time.January, 1, 0, 0, 0, 0, settings.Location())
+		to := time.Date(year+3, time.January, 1, 0, 0, 0, 0, settings.Location())
+		for _, task := range tasks {
+			if task.DueDate != nil && task.DueDate.Before(from) {
+				from = settings.StartOfDay(*task.DueDate)
+			}
+			if task.DueDate != nil && task.DueDate.After(to) {
+				to = task.DueDate.AddDate(0, 0, 1)
+			}
+		}
+		w.VTimezone(settings.Location(), from, to)
+	}
+}
+
+// writeTaskComponent writes one task. Tasks due at local midnight are
+// treated as due on that day rather than at a time. Only VTODOs may be
+// written for tasks without a due date.
+func writeTaskComponent(w *utils.ICalBuilder, task models.Task, settings models.HouseholdSettings, component string) {
+	var due time.Time
+	allDay := false
+	if task.DueDate != nil {
+		due = task.DueDate.In(settings.Location())
+		allDay = due.Equal(settings.StartOfDay(due))
+	}
+
+	w.Begin(component)
+	w.Property("UID", taskUID(task))
+	w.Property("DTSTAMP", utils.ICalTime(task.UpdatedAt))
+	w.Property("CREATED", utils.ICalTime(task.CreatedAt))
+	w.Property("LAST-MODIFIED", utils.ICalTime(task.UpdatedAt))
+
+	summary := task.Title
+	if component == calendarEvent && task.Completed {
+		summary = "✓ " + summary
+	}
+	w.Text("SUMMARY", summary)
+	if task.Description != "" {
+		w.Text("DESCRIPTION", task.Description)
+	}
+	w.Text("CATEGORIES", string(task.Category))
+	w.Property("PRIORITY", strconv.Itoa(icalPriority(task.Priority)))
+
+	switch component {
+	case calendarTodo:
+		if task.DueDate != nil {
+			if task.Recurrence != "" {
+				// Recurring to-dos repeat from their start
+				writeCalendarTime(w, "DTSTART", due, allDay, settings)
+			}
+			writeCalendarTime(w, "DUE", due, allDay, settings)
+		}
+		if task.Completed {
+			w.Property("STATUS", "COMPLETED")
+			w.Property("PERCENT-COMPLETE", "100")
+			if task.CompletedAt != nil {
+				w.Property("COMPLETED", utils.ICalTime(*task.CompletedAt))
+			}
+		} else {
+			w.Property("STATUS", "NEEDS-ACTION")
+		}
+	case calendarEvent:
+		writeCalendarTime(w, "DTSTART", due, allDay, settings)
+		if allDay {
+			writeCalendarTime(w, "DTEND", due.AddDate(0, 0, 1), allDay, settings)
+		} else {
+			w.Property("DURATION", timedEventDuration)
+		}
+		w.Property("STATUS", "CONFIRMED")
+		w.Property("TRANSP", "TRANSPARENT")
+	}
+
+	if task.Recurrence != "" && task.DueDate != nil {
+		if rule, err := utils.ParseRRule(task.Recurrence, settings.Location()); err == nil {
+			w.Property("RRULE", rule.Format(allDay, settings.Location()))
+		}
+	}
+
+	if task.Creator.ID != "" {
+		w.Property("ORGANIZER;CN="+utils.ICalParamValue(task.Creator.Name), "urn:uuid:"+task.Creator.ID)
+	}
+	for _, assignment := range task.Assignments {
+		partstat := "NEEDS-ACTION"
+		if component == calendarEvent {
+			partstat = "ACCEPTED"
+		} else if task.Completed {
+			partstat = "COMPLETED"
+		}
+		w.Property("ATTENDEE;CN="+utils.ICalParamValue(assignment.User.Name)+";ROLE=REQ-PARTICIPANT;PARTSTAT="+partstat,
+			"urn:uuid:"+assignment.UserID)
+	}
+
+	w.End(component)
+}
+
+// writeCalendarTime writes a date or date-time property in the household's
+// time zone. UTC households get plain UTC times and no VTIMEZONE.
+func writeCalendarTime(w *utils.ICalBuilder, name string, t time.Time, allDay bool, settings models.HouseholdSettings) {
+	switch {
+	case allDay:
+		w.Property(name+";VALUE=DATE", utils.ICalDate(t))
+	case settings.Timezone == models.DefaultTimezone:
+		w.Property(name, utils.ICalTime(t))
+	default:
+		w.Property(name+";TZID="+utils.ICalParamValue(settings.Timezone), utils.ICalLocalTime(t))
+	}
+}
+
+// icalPriority maps a priority to the iCalendar scale, where 1 is the
+// highest, 5 medium and 9 the lowest
+func icalPriority(priority models.TaskPriority) int {
+	switch priority {
+	case models.PriorityHigh:
+		return 1
+	case models.PriorityLow:
+		return 9
+	}
+	return 5
+}
+
+// taskPriority reads an iCalendar PRIORITY; 0 means undefined
+func taskPriority(value int) models.TaskPriority {
+	switch {
+	case value >= 1 && value <= 4:
+		return models.PriorityHigh
+	case value >= 6 && value <= 9:
+		return models.PriorityLow
+	}
+	return models.PriorityNormal
+}
@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"household-todo-backend/config"
+	"household-todo-backend/models"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// pairingCodeTTL is how long a pairing code can be redeemed after it is issued
+const pairingCodeTTL = 10 * time.Minute
+
+type DeviceController struct {
+	DB *gorm.DB
+}
+
+func NewDeviceController(db *gorm.DB) *DeviceController {
+	return &DeviceController{DB: db}
+}
+
+type PairDeviceRequest struct {
+	Code     string `json:"code" binding:"required"`
+	DeviceID string `json:"deviceId" binding:"required"`
+	Name     string `json:"name"`
+}
+
+// GetDevices lists the devices signed in as the authenticated user
+func (dc *DeviceController) GetDevices(c *gin.Context) {
+	userID := c.GetString("userID")
+	deviceID := c.GetString("deviceID")
+
+	var devices []models.Device
+	if err := dc.DB.Where("user_id = ?", userID).Order("created_at").Find(&devices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch devices"})
+		return
+	}
+
+	for i := range devices {
+		devices[i].Current = devices[i].DeviceID == deviceID
+	}
+
+	c.JSON(http.StatusOK, devices)
+}
+
+// RemoveDevice signs one of the user's other devices out
+func (dc *DeviceController) RemoveDevice(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	deviceID := c.GetString("deviceID")
+
+	var device models.Device
+	if err := dc.DB.Where("id = ? AND user_id = ?", id, userID).First(&device).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Device not found"})
+		return
+	}
+
+	if device.DeviceID == deviceID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot remove the device you are using"})
+		return
+	}
+
+	var user models.User
+	if err := dc.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	err := dc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&device).Error; err != nil {
+			return err
+		}
+		if err := revokeSessions(tx, "device removed", "device_id = ? AND user_id = ?", device.DeviceID, userID); err != nil {
+			return err
+		}
+
+		// Keep the user's original device pointing at a device they still have
+		if user.DeviceID == device.DeviceID {
+			return tx.Model(&user).Update("device_id", deviceID).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove device"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Device removed successfully"})
+}
+
+// CreatePairingCode issues a short-lived code another device can redeem to
+// sign in as the authenticated user
+func (dc *DeviceController) CreatePairingCode(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	code := utils.GenerateInviteCode(utils.InviteCodeLength)
+	pairing := models.PairingCode{
+		UserID:    userID,
+		CodeHash:  utils.HashToken(code),
+		ExpiresAt: time.Now().Add(pairingCodeTTL),
+	}
+
+	err := dc.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the most recent code is valid
+		if err := tx.Where("user_id = ? AND redeemed_at IS NULL", userID).Delete(&models.PairingCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&pairing).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create pairing code"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":          code,
+		"formattedCode": utils.FormatInviteCode(code),
+		"link":          config.DeepLink("pair/" + code),
+		"expiresAt":     pairing.ExpiresAt,
+	})
+}
+
+// GetPairingQRCode renders a QR code for one of the user's pending pairing codes
+func (dc *DeviceController) GetPairingQRCode(c *gin.Context) {
+	userID := c.GetString("userID")
+	code := utils.NormalizeInviteCode(c.Query("code"))
+
+	var pairing models.PairingCode
+	if err := dc.DB.Where("code_hash = ? AND user_id = ? AND redeemed_at IS NULL AND expires_at > ?", utils.HashToken(code), userID, time.Now()).
+		First(&pairing).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pairing code not found"})
+		return
+	}
+
+	writeQRCode(c, config.DeepLink("pair/"+code))
+}
+
+// PairDevice redeems a pairing code and signs the new device in as the user
+// who issued it
+func (dc *DeviceController) PairDevice(c *gin.Context) {
+	var req PairDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := utils.NormalizeInviteCode(req.Code)
+	if !utils.ValidInviteCode(code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pairing code"})
+		return
+	}
+
+	var pairing models.PairingCode
+	if err := dc.DB.Where("code_hash = ? AND redeemed_at IS NULL AND expires_at > ?", utils.HashToken(code), time.Now()).
+		First(&pairing).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pairing code not found or expired"})
+		return
+	}
+
+	var user models.User
+	if err := dc.DB.Where("id = ?", pairing.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var existing models.Device
+	if err := dc.DB.Where("device_id = ?", req.DeviceID).First(&existing).Error; err == nil && existing.UserID != user.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Device is already signed in as another user"})
+		return
+	}
+
+	now := time.Now()
+	device := existing
+	var tokens authTokens
+	err := dc.DB.Transaction(func(tx *gorm.DB) error {
+		// Redeem atomically so a code cannot be used twice
+		result := tx.Model(&models.PairingCode{}).
+			Where("id = ? AND redeemed_at IS NULL", pairing.ID).
+			Updates(map[string]interface{}{"redeemed_at": now, "redeemed_device_id": req.DeviceID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if device.ID == "" {
+			device = models.Device{
+				UserID:   user.ID,
+				DeviceID: req.DeviceID,
+				Name:     req.Name,
+			}
+		} else if req.Name != "" {
+			device.Name = req.Name
+		}
+		device.LastSeen = &now
+		if err := tx.Save(&device).Error; err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = createSession(tx, c, user.ID, user.HouseholdID, device.DeviceID)
+		return err
+	})
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pairing code not found or expired"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pair device"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token":        tokens.Token,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+		"user":         user,
+		"device":       device,
+	})
+}
@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultEffortMinutes is what a task without an estimate weighs when
+	// effort is measured by estimate
+	defaultEffortMinutes = 15
+
+	// fairShareTolerance is how far, relative to their fair share, a
+	// member's share can stray before they count as over or under loaded
+	fairShareTolerance = 0.25
+
+	// imbalanceThreshold is the imbalance from which a household is flagged
+	// as imbalanced
+	imbalanceThreshold = 0.15
+)
+
+// Workload statuses of a member
+const (
+	loadOver     = "over"
+	loadUnder    = "under"
+	loadBalanced = "balanced"
+)
+
+type FairnessController struct {
+	DB *gorm.DB
+}
+
+func NewFairnessController(db *gorm.DB) *FairnessController {
+	return &FairnessController{DB: db}
+}
+
+// MemberWorkload is one member's part of a household's effort: what they
+// completed in the window and the open tasks assigned to them now
+type MemberWorkload struct {
+	UserID          string     `json:"userId"`
+	Name            string     `json:"name"`
+	AwayUntil       *time.Time `json:"awayUntil"` // nil unless away now
+	CompletedTasks  int        `json:"completedTasks"`
+	CompletedEffort float64    `json:"completedEffort"`
+	CompletedShare  float64    `json:"completedShare"`
+	AssignedTasks   int        `json:"assignedTasks"`
+	AssignedEffort  float64    `json:"assignedEffort"`
+	AssignedShare   float64    `json:"assignedShare"`
+	Share           float64    `json:"share"` // Of completed and assigned effort together
+	FairShare       float64    `json:"fairShare"`
+	Difference      float64    `json:"difference"` // Share minus fair share
+	Status          string     `json:"status"`     // over, under or balanced
+}
+
+// Workload is the effort of a household's members over a window
+type Workload struct {
+	Window          string           `json:"window"`
+	From            *time.Time       `json:"from"`
+	Weight          string           `json:"weight"`
+	CompletedEffort float64          `json:"completedEffort"`
+	AssignedEffort  float64          `json:"assignedEffort"`
+	Imbalance       float64          `json:"imbalance"` // Share of the effort that would have to move for everyone to do the same
+	Imbalanced      bool             `json:"imbalanced"`
+	MostLoaded      *string          `json:"mostLoaded"`
+	LeastLoaded     *string          `json:"leastLoaded"`
+	Members         []MemberWorkload `json:"members"`
+}
+
+// GetFairness reports each member's share of the household's effort: the
+// tasks they completed in the current week, month or all time, and the open
+// tasks assigned to them. Effort is weighed by estimate, points or task
+// count.
+func (fc *FairnessController) GetFairness(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	window, weight, ok := workloadOptions(c, c.Query("window"), c.Query("weight"))
+	if !ok {
+		return
+	}
+
+	workload, err := householdWorkload(fc.DB, householdID, window, weight, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute workload"})
+		return
+	}
+
+	c.JSON(http.StatusOK, workload)
+}
+
+// workloadOptions applies the defaults to a window and a weight, answering
+// with an error when either is unknown
+func workloadOptions(c *gin.Context, window, weight string) (string, string, bool) {
+	if window == "" {
+		window = "month"
+	}
+	if window != "week" && window != "month" && window != "all" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be week, month or all"})
+		return "", "", false
+	}
+	if weight == "" {
+		weight = "estimate"
+	}
+	if weight != "estimate" && weight != "points" && weight != "count" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "weight must be estimate, points or count"})
+		return "", "", false
+	}
+	return window, weight, true
+}
+
+// householdWorkload adds up the effort of a household's current members.
+// Completions come from the points ledger, so undone ones do not count;
+// open tasks count for each of their assignees in equal parts. The task
+// named by excludeTaskID is left out of the open tasks.
+func householdWorkload(db *gorm.DB, householdID, window, weight, excludeTaskID string) (*Workload, error) {
+	now := time.Now()
+	settings := models.GetHouseholdSettings(db, householdID)
+	from, _ := windowStart(settings, window, now)
+
+	members, err := models.HouseholdMembers(db, householdID)
+	if err != nil {
+		return nil, err
+	}
+	workload := &Workload{Window: window, From: from, Weight: weight, Members: make([]MemberWorkload, len(members))}
+	byUser := make(map[string]*MemberWorkload, len(members))
+	for i, member := range members {
+		workload.Members[i] = MemberWorkload{UserID: member.ID, Name: member.Name}
+		if member.IsAway(now) {
+			workload.Members[i].AwayUntil = member.AwayUntil
+		}
+		byUser[member.ID] = &workload.Members[i]
+	}
+
+	completions := db.Scopes(models.ValidCompletions).Where("household_id = ?", householdID)
+	if from != nil {
+		completions = completions.Where("created_at >= ?", *from)
+	}
+	var credits []models.PointsEntry
+	if err := completions.Find(&credits).Error; err != nil {
+		return nil, err
+	}
+
+	// Estimates are read from the tasks; deleted ones weigh the default
+	var taskIDs []string
+	for _, credit := range credits {
+		if credit.TaskID != nil {
+			taskIDs = append(taskIDs, *credit.TaskID)
+		}
+	}
+	estimates := map[string]int{}
+	if len(taskIDs) > 0 {
+		var tasks []models.Task
+		if err := db.Select("id", "estimate").Where("id IN ?", taskIDs).Find(&tasks).Error; err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			estimates[task.ID] = task.Estimate
+		}
+	}
+	for _, credit := range credits {
+		member, ok := byUser[credit.UserID]
+		if !ok {
+			continue
+		}
+		estimate := 0
+		if credit.TaskID != nil {
+			estimate = estimates[*credit.TaskID]
+		}
+		effort := taskEffort(weight, credit.Points, estimate)
+		member.CompletedTasks++
+		member.CompletedEffort += effort
+		workload.CompletedEffort += effort
+	}
+
+	var open []models.Task
+	if err := db.Where("household_id = ? AND completed = ? AND id != ?", householdID, false, excludeTaskID).
+		Preload("Assignments").
+		Find(&open).Error; err != nil {
+		return nil, err
+	}
+	for _, task := range open {
+		var assignees []*MemberWorkload
+		for _, assignment := range task.Assignments {
+			if member, ok := byUser[assignment.UserID]; ok {
+				assignees = append(assignees, member)
+			}
+		}
+		if len(assignees) == 0 {
+			continue
+		}
+		effort := taskEffort(weight, task.Points, task.Estimate)
+		for _, member := range assignees {
+			member.AssignedTasks++
+			member.AssignedEffort += effort / float64(len(assignees))
+		}
+		workload.AssignedEffort += effort
+	}
+
+	total := workload.CompletedEffort + workload.AssignedEffort
+	for i := range workload.Members {
+		member := &workload.Members[i]
+		member.FairShare = 1 / float64(len(workload.Members))
+		member.CompletedShare = fraction(member.CompletedEffort, workload.CompletedEffort)
+		member.AssignedShare = fraction(member.AssignedEffort, workload.AssignedEffort)
+		member.Share = fraction(member.CompletedEffort+member.AssignedEffort, total)
+		member.Status = loadBalanced
+		if total > 0 {
+			member.Difference = member.Share - member.FairShare
+			workload.Imbalance += math.Abs(member.Difference) / 2
+			if member.Difference > member.FairShare*fairShareTolerance {
+				member.Status = loadOver
+			} else if -member.Difference > member.FairShare*fairShareTolerance {
+				member.Status = loadUnder
+			}
+		}
+	}
+
+	sort.SliceStable(workload.Members, func(i, j int) bool {
+		return workload.Members[i].Share > workload.Members[j].Share
+	})
+	if total > 0 && len(workload.Members) > 1 {
+		workload.MostLoaded = &workload.Members[0].UserID
+		workload.LeastLoaded = &workload.Members[len(workload.Members)-1].UserID
+		workload.Imbalanced = workload.Imbalance >= imbalanceThreshold
+	}
+
+	workload.CompletedEffort = round3(workload.CompletedEffort)
+	workload.AssignedEffort = round3(workload.AssignedEffort)
+	workload.Imbalance = round3(workload.Imbalance)
+	for i := range workload.Members {
+		member := &workload.Members[i]
+		member.CompletedEffort = round3(member.CompletedEffort)
+		member.CompletedShare = round3(member.CompletedShare)
+		member.AssignedEffort = round3(member.AssignedEffort)
+		member.AssignedShare = round3(member.AssignedShare)
+		member.Share = round3(member.Share)
+		member.FairShare = round3(member.FairShare)
+		member.Difference = round3(member.Difference)
+	}
+	return workload, nil
+}
+
+// taskEffort weighs a task by its estimate in minutes, its points or as one
+func taskEffort(weight string, points, estimate int) float64 {
+	switch weight {
+	case "points":
+		return float64(points)
+	case "count":
+		return 1
+	}
+	if estimate > 0 {
+		return float64(estimate)
+	}
+	return defaultEffortMinutes
+}
+
+// fraction is part of whole, or 0 when whole is nothing
+func fraction(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part / whole
+}
+
+func round3(x float64) float64 {
+	return math.Round(x*1000) / 1000
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// feedMaxAge is how long calendar apps may cache a feed before polling again
+const feedMaxAge = "private, max-age=900"
+
+type FeedController struct {
+	DB *gorm.DB
+}
+
+func NewFeedController(db *gorm.DB) *FeedController {
+	return &FeedController{DB: db}
+}
+
+type CreateFeedRequest struct {
+	Scope string `json:"scope" binding:"required,oneof=user household"`
+	Name  string `json:"name"`
+}
+
+// CreateFeed issues a calendar feed token for the authenticated user in their
+// current household. The feed URLs are only returned in this response.
+func (fc *FeedController) CreateFeed(c *gin.Context) {
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	var req CreateFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = "My tasks"
+		if req.Scope == models.FeedScopeHousehold {
+			name = "Household tasks"
+		}
+	}
+
+	secret := models.FeedTokenPrefix + utils.GenerateSecureToken(32)
+	feed := models.FeedToken{
+		UserID:      userID,
+		HouseholdID: householdID,
+		Scope:       req.Scope,
+		Name:        name,
+		TokenPrefix: secret[:len(models.FeedTokenPrefix)+6],
+		TokenHash:   utils.HashToken(secret),
+	}
+
+	if err := fc.DB.Create(&feed).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create feed"})
+		return
+	}
+
+	url := publicBaseURL(c) + "/feeds/" + secret + ".ics"
+	c.JSON(http.StatusCreated, gin.H{
+		"token":     secret,
+		"url":       url,
+		"webcalUrl": "webcal://" + url[strings.Index(url, "://")+3:],
+		"feed":      feed,
+	})
+}
+
+// GetFeeds lists the authenticated user's active calendar feeds
+func (fc *FeedController) GetFeeds(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var feeds []models.FeedToken
+	if err := fc.DB.Where("user_id = ? AND revoked_at IS NULL", userID).Order("created_at DESC").Find(&feeds).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feeds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+// RevokeFeed stops one of the authenticated user's calendar feeds from working
+func (fc *FeedController) RevokeFeed(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+
+	var feed models.FeedToken
+	if err := fc.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).First(&feed).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feed not found"})
+		return
+	}
+
+	if err := fc.DB.Model(&feed).Update("revoked_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Feed revoked successfully"})
+}
+
+// GetFeed serves a calendar feed to calendar apps. The token in the URL is
+// the only credential, so unknown and revoked tokens both get a plain 404.
+func (fc *FeedController) GetFeed(c *gin.Context) {
+	secret := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	var feed models.FeedToken
+	if err := fc.DB.Where("token_hash = ? AND revoked_at IS NULL", utils.HashToken(secret)).First(&feed).Error; err != nil {
+		c.String(http.StatusNotFound, "Feed not found")
+		return
+	}
+
+	component := calendarEvent
+	switch c.DefaultQuery("component", "event") {
+	case "event":
+	case "todo":
+		component = calendarTodo
+	default:
+		c.String(http.StatusBadRequest, "component must be todo or event")
+		return
+	}
+
+	var household models.Household
+	if err := fc.DB.Where("id = ?", feed.HouseholdID).First(&household).Error; err != nil {
+		c.String(http.StatusNotFound, "Feed not found")
+		return
+	}
+
+	query := fc.DB.Where("household_id = ? AND due_date IS NOT NULL", feed.HouseholdID)
+	name := household.Name
+	if feed.Scope == models.FeedScopeUser {
+		query = query.Where("id IN (?)", fc.DB.Model(&models.TaskAssignment{}).Select("task_id").Where("user_id = ?", feed.UserID))
+		name = feed.Name + " · " + household.Name
+	}
+
+	var tasks []models.Task
+	if err := query.
+		Preload("Creator").
+		Preload("Assignments.User").
+		Order("due_date, created_at").
+		Find(&tasks).Error; err != nil {
+		c.String(http.StatusInternalServerError, "Failed to fetch tasks")
+		return
+	}
+
+	now := time.Now()
+	fc.DB.Model(&feed).Update("last_used_at", now)
+
+	settings := models.GetHouseholdSettings(fc.DB, feed.HouseholdID)
+	body := taskCalendar(name, tasks, settings, component)
+
+	// DTSTAMP values change with the tasks, so the body hash is a stable ETag
+	sum := sha256.Sum256([]byte(body))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	lastModified := household.CreatedAt
+	for _, task := range tasks {
+		if task.UpdatedAt.After(lastModified) {
+			lastModified = task.UpdatedAt
+		}
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", feedMaxAge)
+	if match := c.GetHeader("If-None-Match"); match != "" && strings.Contains(match, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(body))
+}
+
+// publicBaseURL is the public origin feed and download URLs are built on.
+// PUBLIC_BASE_URL overrides the request's host for deployments behind a proxy.
+func publicBaseURL(c *gin.Context) string {
+	if base := os.Getenv("PUBLIC_BASE_URL"); base != "" {
+		return strings.TrimSuffix(base, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"household-todo-backend/config"
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// newTestDB opens a fresh database for one test with every model migrated
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(config.SQLiteDSN(filepath.Join(t.TempDir(), "test.db"))), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetupJoinTable(&models.Household{}, "Users", &models.Membership{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(
+		&models.Household{},
+		&models.User{},
+		&models.Task{},
+		&models.TaskAssignment{},
+		&models.TaskTombstone{},
+		&models.TaskChecklistItem{},
+		&models.TaskDependency{},
+		&models.TaskAttachment{},
+		&models.TimeEntry{},
+		&models.Notification{},
+		&models.PointsEntry{},
+		&models.Membership{},
+		&models.HouseholdSettings{},
+		&models.AuditEvent{},
+	); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestHousehold creates a household with one admin and returns both IDs
+func newTestHousehold(t *testing.T, db *gorm.DB) (householdID, userID string) {
+	t.Helper()
+	household := models.Household{Name: "Home", InviteCode: "TESTCODE"}
+	if err := db.Create(&household).Error; err != nil {
+		t.Fatal(err)
+	}
+	user := models.User{Name: "Sam", DeviceID: "device-1", HouseholdID: household.ID, IsActive: true}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if err := db.Create(&models.Membership{UserID: user.ID, HouseholdID: household.ID, Role: models.RoleAdmin, LastSeenAt: &now}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return household.ID, user.ID
+}
+
+// authenticated stands in for the auth middleware, signing every request in
+// as the given user and household
+func authenticated(userID, householdID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Set("householdID", householdID)
+		c.Next()
+	}
+}
+
+func serve(r http.Handler, method, path, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
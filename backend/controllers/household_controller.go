@@ -1,9 +1,13 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"household-todo-backend/config"
 	"household-todo-backend/models"
 	"household-todo-backend/utils"
 
@@ -25,11 +29,27 @@ type CreateHouseholdRequest struct {
 	DeviceID string `json:"deviceId" binding:"required"`
 }
 
+type UpdateHouseholdRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type DeleteHouseholdRequest struct {
+	Confirm string `json:"confirm" binding:"required"`
+}
+
 type JoinHouseholdRequest struct {
 	Name     string `json:"name" binding:"required"`
 	DeviceID string `json:"deviceId" binding:"required"`
 }
 
+type CreateAnotherHouseholdRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type JoinAnotherHouseholdRequest struct {
+	InviteCode string `json:"inviteCode" binding:"required"`
+}
+
 // CreateHousehold creates a new household and first user
 func (hc *HouseholdController) CreateHousehold(c *gin.Context) {
 	var req CreateHouseholdRequest
@@ -38,19 +58,19 @@ func (hc *HouseholdController) CreateHousehold(c *gin.Context) {
 		return
 	}
 
-	// Generate a unique invite code
-	inviteCode := utils.GenerateInviteCode(8)
-
-	// Check if invite code already exists (very unlikely but possible)
-	for {
-		var count int64
-		hc.DB.Model(&models.Household{}).Where("invite_code = ?", inviteCode).Count(&count)
-		if count == 0 {
-			break
-		}
-		inviteCode = utils.GenerateInviteCode(8)
+	// A device can only be signed in as one user. Signed-in users create
+	// further households with POST /api/me/households, so a device ID
+	// alone never signs anyone in.
+	var deviceCount int64
+	hc.DB.Model(&models.Device{}).Where("device_id = ?", req.DeviceID).Count(&deviceCount)
+	if deviceCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Device is already registered"})
+		return
 	}
 
+	// Generate a unique invite code
+	inviteCode := hc.generateUniqueInviteCode()
+
 	// Start transaction
 	tx := hc.DB.Begin()
 	defer func() {
@@ -71,6 +91,8 @@ func (hc *HouseholdController) CreateHousehold(c *gin.Context) {
 		return
 	}
 
+	now := time.Now()
+
 	// Create first user
 	user := models.User{
 		Name:        req.UserName,
@@ -78,8 +100,6 @@ func (hc *HouseholdController) CreateHousehold(c *gin.Context) {
 		HouseholdID: household.ID,
 		IsActive:    true,
 	}
-
-	now := time.Now()
 	user.LastSeen = &now
 
 	if err := tx.Create(&user).Error; err != nil {
@@ -88,8 +108,34 @@ func (hc *HouseholdController) CreateHousehold(c *gin.Context) {
 		return
 	}
 
+	device := models.Device{
+		UserID:   user.ID,
+		DeviceID: req.DeviceID,
+		LastSeen: &now,
+	}
+
+	if err := tx.Create(&device).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	// The creator administers the household
+	membership := models.Membership{
+		UserID:      user.ID,
+		HouseholdID: household.ID,
+		Role:        models.RoleAdmin,
+		LastSeenAt:  &now,
+	}
+
+	if err := tx.Create(&membership).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create household"})
+		return
+	}
+
 	// Generate JWT token
-	token, err := utils.GenerateJWT(user.ID, household.ID, user.DeviceID)
+	tokens, err := createSession(tx, c, user.ID, household.ID, req.DeviceID)
 	if err != nil {
 		tx.Rollback()
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
@@ -103,19 +149,18 @@ func (hc *HouseholdController) CreateHousehold(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"token":     token,
-		"household": household,
-		"user":      user,
+		"token":        tokens.Token,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+		"household":    household,
+		"user":         user,
 	})
 }
 
 // GetHouseholdByCode retrieves a household by its invite code
 func (hc *HouseholdController) GetHouseholdByCode(c *gin.Context) {
-	code := c.Param("code")
-
-	var household models.Household
-	if err := hc.DB.Where("invite_code = ?", code).First(&household).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+	household, ok := hc.householdByInviteCode(c, c.Param("code"))
+	if !ok {
 		return
 	}
 
@@ -124,39 +169,23 @@ func (hc *HouseholdController) GetHouseholdByCode(c *gin.Context) {
 
 // JoinHousehold adds a user to an existing household
 func (hc *HouseholdController) JoinHousehold(c *gin.Context) {
-	code := c.Param("code")
 	var req JoinHouseholdRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var household models.Household
-	if err := hc.DB.Where("invite_code = ?", code).First(&household).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+	household, ok := hc.householdByInviteCode(c, c.Param("code"))
+	if !ok {
 		return
 	}
 
-	// Check if device is already registered in this household
-	var existingUser models.User
-	if err := hc.DB.Where("device_id = ? AND household_id = ?", req.DeviceID, household.ID).First(&existingUser).Error; err == nil {
-		// Device already exists, update user name if different
-		if existingUser.Name != req.Name {
-			existingUser.Name = req.Name
-			hc.DB.Save(&existingUser)
-		}
-
-		// Generate JWT token for existing user
-		token, err := utils.GenerateJWT(existingUser.ID, household.ID, existingUser.DeviceID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"token": token,
-			"user":  existingUser,
-		})
+	// A device can only be signed in as one user. Signed-in users join
+	// further households with POST /api/me/households/join.
+	var deviceCount int64
+	hc.DB.Model(&models.Device{}).Where("device_id = ?", req.DeviceID).Count(&deviceCount)
+	if deviceCount > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Device is already registered"})
 		return
 	}
 
@@ -170,21 +199,162 @@ func (hc *HouseholdController) JoinHousehold(c *gin.Context) {
 	now := time.Now()
 	user.LastSeen = &now
 
-	if err := hc.DB.Create(&user).Error; err != nil {
+	var tokens authTokens
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.Device{
+			UserID:   user.ID,
+			DeviceID: req.DeviceID,
+			LastSeen: &now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.Membership{
+			UserID:      user.ID,
+			HouseholdID: household.ID,
+			Role:        models.RoleMember,
+			LastSeenAt:  &now,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Start a session for the new user
+		var err error
+		tokens, err = createSession(tx, c, user.ID, household.ID, user.DeviceID)
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join household"})
 		return
 	}
 
-	// Generate JWT token for new user
-	token, err := utils.GenerateJWT(user.ID, household.ID, user.DeviceID)
+	c.JSON(http.StatusCreated, gin.H{
+		"token":        tokens.Token,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+		"user":         user,
+	})
+}
+
+// CreateAnotherHousehold creates a household for the signed-in user, who
+// becomes its admin, and rescopes the calling session to it
+func (hc *HouseholdController) CreateAnotherHousehold(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req CreateAnotherHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var session models.Session
+	if err := hc.DB.Where("id = ? AND user_id = ?", c.GetString("sessionID"), userID).First(&session).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return
+	}
+
+	household := models.Household{
+		Name:       req.Name,
+		InviteCode: hc.generateUniqueInviteCode(),
+	}
+
+	var tokens authTokens
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&household).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Create(&models.Membership{
+			UserID:      userID,
+			HouseholdID: household.ID,
+			Role:        models.RoleAdmin,
+			LastSeenAt:  &now,
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = moveSession(tx, &session, household.ID)
+		return err
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create household"})
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
-		"token": token,
-		"user":  user,
+		"token":        tokens.Token,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+		"household":    household,
+		"role":         models.RoleAdmin,
+	})
+}
+
+// JoinAnotherHousehold adds the signed-in user to the household an invite
+// code belongs to and rescopes the calling session to it
+func (hc *HouseholdController) JoinAnotherHousehold(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req JoinAnotherHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	household, ok := hc.householdByInviteCode(c, req.InviteCode)
+	if !ok {
+		return
+	}
+
+	var session models.Session
+	if err := hc.DB.Where("id = ? AND user_id = ?", c.GetString("sessionID"), userID).First(&session).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return
+	}
+
+	var membership models.Membership
+	joined := false
+	var tokens authTokens
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND household_id = ?", userID, household.ID).First(&membership).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			now := time.Now()
+			membership = models.Membership{
+				UserID:      userID,
+				HouseholdID: household.ID,
+				Role:        models.RoleMember,
+				LastSeenAt:  &now,
+			}
+			err = tx.Create(&membership).Error
+			joined = true
+		}
+		if err != nil {
+			return err
+		}
+
+		tokens, err = moveSession(tx, &session, household.ID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to join household"})
+		return
+	}
+
+	// Already being a member just switches to the household
+	status := http.StatusOK
+	if joined {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{
+		"token":        tokens.Token,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+		"household":    household,
+		"role":         membership.Role,
 	})
 }
 
@@ -199,8 +369,8 @@ func (hc *HouseholdController) GetHouseholdUsers(c *gin.Context) {
 		return
 	}
 
-	var users []models.User
-	if err := hc.DB.Where("household_id = ?", householdID).Find(&users).Error; err != nil {
+	users, err := models.HouseholdMembers(hc.DB, householdID)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
 	}
@@ -220,12 +390,15 @@ func (hc *HouseholdController) GetInviteCode(c *gin.Context) {
 	}
 
 	var household models.Household
-	if err := hc.DB.First(&household, householdID).Error; err != nil {
+	if err := hc.DB.Where("id = ?", householdID).First(&household).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"inviteCode": household.InviteCode})
+	c.JSON(http.StatusOK, gin.H{
+		"inviteCode":          household.InviteCode,
+		"formattedInviteCode": utils.FormatInviteCode(household.InviteCode),
+	})
 }
 
 // RefreshInviteCode generates a new invite code for a household
@@ -240,31 +413,287 @@ func (hc *HouseholdController) RefreshInviteCode(c *gin.Context) {
 	}
 
 	var household models.Household
-	if err := hc.DB.First(&household, householdID).Error; err != nil {
+	if err := hc.DB.Where("id = ?", householdID).First(&household).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
 		return
 	}
 
 	// Generate a new unique invite code
-	inviteCode := utils.GenerateInviteCode(8)
-
-	// Check if invite code already exists (very unlikely but possible)
-	for {
-		var count int64
-		hc.DB.Model(&models.Household{}).Where("invite_code = ?", inviteCode).Count(&count)
-		if count == 0 {
-			break
-		}
-		inviteCode = utils.GenerateInviteCode(8)
-	}
+	inviteCode := hc.generateUniqueInviteCode()
 
 	household.InviteCode = inviteCode
+	household.LegacyInviteCode = false
 	if err := hc.DB.Save(&household).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh invite code"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"inviteCode": household.InviteCode})
+	c.JSON(http.StatusOK, gin.H{
+		"inviteCode":          household.InviteCode,
+		"formattedInviteCode": utils.FormatInviteCode(household.InviteCode),
+	})
+}
+
+// UpdateHousehold renames a household
+func (hc *HouseholdController) UpdateHousehold(c *gin.Context) {
+	householdID := c.Param("id")
+	userID := c.GetString("userID")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	if !models.IsAdmin(hc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only household admins can rename the household"})
+		return
+	}
+
+	var req UpdateHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name cannot be empty"})
+		return
+	}
+
+	var household models.Household
+	if err := hc.DB.Where("id = ?", householdID).First(&household).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+		return
+	}
+
+	oldName := household.Name
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&household).Update("name", name).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, c, householdID, userID, models.AuditHouseholdRenamed, fmt.Sprintf("%q to %q", oldName, name))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rename household"})
+		return
+	}
+
+	c.JSON(http.StatusOK, household)
+}
+
+// DeleteHousehold permanently deletes a household with its tasks. Members who
+// belong to no other household are deleted too. The request must repeat the
+// household's name to confirm.
+func (hc *HouseholdController) DeleteHousehold(c *gin.Context) {
+	householdID := c.Param("id")
+	userID := c.GetString("userID")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	if !models.IsAdmin(hc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only household admins can delete the household"})
+		return
+	}
+
+	var req DeleteHouseholdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var household models.Household
+	if err := hc.DB.Where("id = ?", householdID).First(&household).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+		return
+	}
+
+	if strings.TrimSpace(req.Confirm) != household.Name {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Confirmation does not match the household name"})
+		return
+	}
+
+	members, err := models.HouseholdMembers(hc.DB, householdID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete household"})
+		return
+	}
+
+	var files, avatars []string
+	err = hc.DB.Transaction(func(tx *gorm.DB) error {
+		for _, member := range members {
+			remainingID, err := detachMember(tx, member, householdID)
+			if err != nil {
+				return err
+			}
+			if remainingID == "" {
+				avatars = append(avatars, member.AvatarKey)
+			}
+		}
+		var err error
+		files, err = purgeHousehold(tx, householdID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete household"})
+		return
+	}
+	removeFiles(files)
+	for _, key := range avatars {
+		removeAvatarFiles(key)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Household deleted successfully"})
+}
+
+// ExportHousehold returns the whole household as a JSON archive that can be
+// imported again
+func (hc *HouseholdController) ExportHousehold(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var household models.Household
+	if err := hc.DB.Where("id = ?", householdID).First(&household).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+		return
+	}
+
+	archive, err := buildHouseholdArchive(hc.DB, household)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export household"})
+		return
+	}
+
+	filename := fmt.Sprintf("household-%s.json", archive.ExportedAt.Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, archive)
+}
+
+// buildHouseholdArchive collects everything stored for a household
+func buildHouseholdArchive(db *gorm.DB, household models.Household) (models.HouseholdArchive, error) {
+	archive := models.HouseholdArchive{
+		Version:    models.ArchiveVersion,
+		ExportedAt: time.Now().UTC(),
+		Household: models.ArchivedHousehold{
+			ID:        household.ID,
+			Name:      household.Name,
+			CreatedAt: household.CreatedAt,
+		},
+		Settings:     models.GetHouseholdSettings(db, household.ID),
+		Members:      []models.ArchivedMember{},
+		Tasks:        []models.ArchivedTask{},
+		Assignments:  []models.ArchivedAssignment{},
+		Checklists:   []models.ArchivedChecklist{},
+		Dependencies: []models.ArchivedDependency{},
+		History:      []models.ArchivedEvent{},
+	}
+
+	var memberships []models.Membership
+	if err := db.Where("household_id = ?", household.ID).Order("created_at").Find(&memberships).Error; err != nil {
+		return archive, err
+	}
+	for _, membership := range memberships {
+		var user models.User
+		if err := db.Where("id = ?", membership.UserID).First(&user).Error; err != nil {
+			return archive, err
+		}
+		archive.Members = append(archive.Members, models.ArchivedMember{
+			ID:       user.ID,
+			Name:     user.Name,
+			Color:    user.Color,
+			Emoji:    user.Emoji,
+			Role:     membership.Role,
+			JoinedAt: membership.CreatedAt,
+			LastSeen: user.LastSeen,
+		})
+	}
+
+	var tasks []models.Task
+	if err := db.Where("household_id = ?", household.ID).
+		Preload("Assignments").
+		Preload("Checklist", orderChecklist).
+		Order("created_at").
+		Find(&tasks).Error; err != nil {
+		return archive, err
+	}
+	for _, task := range tasks {
+		archive.Tasks = append(archive.Tasks, models.ArchivedTask{
+			ID:          task.ID,
+			Title:       task.Title,
+			Description: task.Description,
+			Category:    task.Category,
+			Priority:    task.Priority,
+			Points:      task.Points,
+			Estimate:    task.Estimate,
+			DueDate:     task.DueDate,
+			Recurrence:  task.Recurrence,
+			Completed:   task.Completed,
+			CreatorID:   task.CreatorID,
+			CreatedAt:   task.CreatedAt,
+			UpdatedAt:   task.UpdatedAt,
+			CompletedAt: task.CompletedAt,
+			CompletedBy: task.CompletedBy,
+
+			RequiresVerification: task.RequiresVerification,
+			ReviewerID:           task.ReviewerID,
+		})
+		for _, assignment := range task.Assignments {
+			archive.Assignments = append(archive.Assignments, models.ArchivedAssignment{
+				TaskID:    assignment.TaskID,
+				UserID:    assignment.UserID,
+				CreatedAt: assignment.CreatedAt,
+			})
+		}
+		for _, item := range task.Checklist {
+			archive.Checklists = append(archive.Checklists, models.ArchivedChecklist{
+				TaskID:   item.TaskID,
+				Position: item.Position,
+				Title:    item.Title,
+				Done:     item.Done,
+			})
+		}
+	}
+
+	var dependencies []models.TaskDependency
+	if err := db.Where("household_id = ?", household.ID).Order("created_at").Find(&dependencies).Error; err != nil {
+		return archive, err
+	}
+	for _, dependency := range dependencies {
+		archive.Dependencies = append(archive.Dependencies, models.ArchivedDependency{
+			TaskID:    dependency.TaskID,
+			BlockerID: dependency.BlockerID,
+			CreatedAt: dependency.CreatedAt,
+		})
+	}
+
+	var events []models.AuditEvent
+	if err := db.Where("household_id = ?", household.ID).Order("created_at").Find(&events).Error; err != nil {
+		return archive, err
+	}
+	for _, event := range events {
+		archive.History = append(archive.History, models.ArchivedEvent{
+			UserID:    event.UserID,
+			Action:    event.Action,
+			Detail:    event.Detail,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	return archive, nil
 }
 
 // GetMe returns all data for the authenticated user (bootstrap endpoint)
@@ -274,7 +703,7 @@ func (hc *HouseholdController) GetMe(c *gin.Context) {
 
 	// Get user data
 	var user models.User
-	if err := hc.DB.First(&user, userID).Error; err != nil {
+	if err := hc.DB.Where("id = ?", userID).Preload("Devices").First(&user).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
@@ -282,7 +711,6 @@ func (hc *HouseholdController) GetMe(c *gin.Context) {
 	// Get household with all users and tasks
 	var household models.Household
 	if err := hc.DB.Where("id = ?", householdID).
-		Preload("Users").
 		Preload("Tasks.Creator").
 		Preload("Tasks.Assignments.User").
 		First(&household).Error; err != nil {
@@ -290,8 +718,97 @@ func (hc *HouseholdController) GetMe(c *gin.Context) {
 		return
 	}
 
+	users, err := models.HouseholdMembers(hc.DB, householdID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+	household.Users = users
+
+	settings := models.GetHouseholdSettings(hc.DB, householdID)
+	decorateTasks(settings, household.Tasks)
+	if err := models.LoadDependencies(hc.DB, household.Tasks); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
+	}
+
+	markHouseholdSeen(hc.DB, userID, householdID)
+
 	c.JSON(http.StatusOK, gin.H{
 		"user":      user,
 		"household": household,
+		"settings":  settings,
 	})
 }
+
+// householdByInviteCode finds the household an invite code belongs to. The
+// checksum rejects typos before any lookup; only households flagged with a
+// code from before checksums existed are searched for codes without one.
+func (hc *HouseholdController) householdByInviteCode(c *gin.Context, input string) (models.Household, bool) {
+	code := utils.NormalizeInviteCode(input)
+
+	valid := utils.ValidInviteCode(code)
+	var household models.Household
+	if !valid && !models.HasLegacyInviteCodes() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite code"})
+		return household, false
+	}
+
+	query := hc.DB.Where("invite_code = ?", code)
+	if !valid {
+		query = query.Where("legacy_invite_code = ?", true)
+	}
+	if err := query.First(&household).Error; err != nil {
+		if !valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid invite code"})
+			return household, false
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+		return household, false
+	}
+	return household, true
+}
+
+// generateUniqueInviteCode returns an invite code that no household uses yet
+func (hc *HouseholdController) generateUniqueInviteCode() string {
+	inviteCode := utils.GenerateInviteCode(utils.InviteCodeLength)
+
+	// Check if invite code already exists (very unlikely but possible)
+	for {
+		var count int64
+		hc.DB.Model(&models.Household{}).Where("invite_code = ?", inviteCode).Count(&count)
+		if count == 0 {
+			break
+		}
+		inviteCode = utils.GenerateInviteCode(utils.InviteCodeLength)
+	}
+
+	return inviteCode
+}
+
+// GetInviteQRCode renders a QR code for the household's invite deep link
+func (hc *HouseholdController) GetInviteQRCode(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var household models.Household
+	if err := hc.DB.Where("id = ?", householdID).First(&household).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+		return
+	}
+
+	// A specific code may be requested, e.g. from a printed copy, but it
+	// must still be the household's live invite
+	if code := c.Query("code"); code != "" && utils.NormalizeInviteCode(code) != household.InviteCode {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invite code not found"})
+		return
+	}
+
+	writeQRCode(c, config.DeepLink("join/"+household.InviteCode))
+}
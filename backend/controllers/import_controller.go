@@ -0,0 +1,398 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"household-todo-backend/importers"
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxImportSize caps uploads so a single import cannot exhaust memory
+	maxImportSize = 10 << 20
+
+	// maxImportTasks caps how many tasks a single import may create
+	maxImportTasks = 5000
+)
+
+// errDryRun rolls back a dry-run import after the report has been built
+var errDryRun = errors.New("dry run")
+
+type ImportController struct {
+	DB *gorm.DB
+}
+
+func NewImportController(db *gorm.DB) *ImportController {
+	return &ImportController{DB: db}
+}
+
+// ImportReport describes what an import created, or would create in a dry run
+type ImportReport struct {
+	Format                string           `json:"format"`
+	DryRun                bool             `json:"dryRun"`
+	TasksCreated          int              `json:"tasksCreated"`
+	AssignmentsCreated    int              `json:"assignmentsCreated"`
+	ChecklistItemsCreated int              `json:"checklistItemsCreated"`
+	DependenciesCreated   int              `json:"dependenciesCreated"`
+	SettingsApplied       bool             `json:"settingsApplied"`
+	MatchedMembers        []ImportedMember `json:"matchedMembers"`
+	UnmatchedMembers      []string         `json:"unmatchedMembers"`
+	Warnings              []string         `json:"warnings"`
+	Tasks                 []ImportedTask   `json:"tasks"`
+}
+
+// ImportedMember pairs a person named in the import with the member they
+// were matched to
+type ImportedMember struct {
+	Name   string `json:"name"`
+	UserID string `json:"userId"`
+}
+
+// ImportedTask is a summary of one task created by an import
+type ImportedTask struct {
+	Title     string              `json:"title"`
+	Category  models.TaskCategory `json:"category"`
+	DueDate   *time.Time          `json:"dueDate"`
+	Completed bool                `json:"completed"`
+	Creator   string              `json:"creator"`
+	Assignees []string            `json:"assignees"`
+}
+
+// ImportHousehold adds the tasks from an export of this backend or another
+// todo app to the authenticated household. People in the import are matched
+// to members by name; tasks from anyone else are attributed to the importer.
+func (ic *ImportController) ImportHousehold(c *gin.Context) {
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	if !models.IsAdmin(ic.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only household admins can import data"})
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+	includeSettings, _ := strconv.ParseBool(c.Query("includeSettings"))
+
+	data, contentType, err := readImportUpload(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		if format, err = importers.Detect(contentType, data); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Could not detect the import format; pass ?format=native|csv|todoist|trello"})
+			return
+		}
+	}
+
+	settings := models.GetHouseholdSettings(ic.DB, householdID)
+	archive, err := importers.Parse(format, data, settings.Location())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(archive.Tasks) > maxImportTasks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Imports are limited to %d tasks", maxImportTasks)})
+		return
+	}
+
+	report := ImportReport{Format: format, DryRun: dryRun}
+	err = ic.DB.Transaction(func(tx *gorm.DB) error {
+		if err := applyImport(tx, archive, householdID, userID, includeSettings && format == importers.FormatNative, &report); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import data"})
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	c.JSON(status, report)
+}
+
+// readImportUpload returns the uploaded file from a multipart "file" field,
+// or the raw request body otherwise
+func readImportUpload(c *gin.Context) ([]byte, string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", errors.New("missing file upload")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, "", err
+		}
+		contentType := header.Header.Get("Content-Type")
+		if strings.HasSuffix(strings.ToLower(header.Filename), ".csv") {
+			contentType = "text/csv"
+		}
+		return data, contentType, nil
+	}
+
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, "", errors.New("upload is too large")
+	}
+	if len(data) == 0 {
+		return nil, "", errors.New("empty upload")
+	}
+	return data, c.ContentType(), nil
+}
+
+// applyImport creates the archive's tasks and assignments in the household
+// with new IDs and fills in the report
+func applyImport(tx *gorm.DB, archive *models.HouseholdArchive, householdID, importerID string, includeSettings bool, report *ImportReport) error {
+	report.MatchedMembers = []ImportedMember{}
+	report.UnmatchedMembers = []string{}
+	report.Warnings = []string{}
+	report.Tasks = []ImportedTask{}
+
+	members, err := models.HouseholdMembers(tx, householdID)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]models.User)
+	for _, member := range members {
+		key := strings.ToLower(strings.TrimSpace(member.Name))
+		if _, dup := byName[key]; !dup {
+			byName[key] = member
+		}
+	}
+
+	// Map the archive's member IDs to household members
+	userIDs := make(map[string]string)
+	names := make(map[string]string)
+	for _, member := range members {
+		names[member.ID] = member.Name
+	}
+	for _, member := range archive.Members {
+		if user, ok := byName[strings.ToLower(strings.TrimSpace(member.Name))]; ok {
+			userIDs[member.ID] = user.ID
+			report.MatchedMembers = append(report.MatchedMembers, ImportedMember{Name: member.Name, UserID: user.ID})
+		} else {
+			report.UnmatchedMembers = append(report.UnmatchedMembers, member.Name)
+		}
+	}
+	if len(report.UnmatchedMembers) > 0 {
+		report.Warnings = append(report.Warnings, "Tasks created by unmatched people are attributed to you and their assignments are skipped")
+	}
+
+	settings := models.GetHouseholdSettings(tx, householdID)
+	if includeSettings {
+		imported := archive.Settings
+		imported.HouseholdID = householdID
+		if _, err := time.LoadLocation(imported.Timezone); err != nil || imported.Timezone == "" || !imported.DefaultCategory.Valid() {
+			report.Warnings = append(report.Warnings, "Archive settings are invalid and were not applied")
+		} else {
+			if err := tx.Save(&imported).Error; err != nil {
+				return err
+			}
+			settings = imported
+			report.SettingsApplied = true
+		}
+	}
+
+	now := time.Now()
+	taskIDs := make(map[string]string)
+	summaries := make(map[string]int)
+	for _, archived := range archive.Tasks {
+		title := strings.TrimSpace(archived.Title)
+		if title == "" {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("Skipped task %s without a title", archived.ID))
+			continue
+		}
+
+		category := archived.Category
+		if !category.Valid() {
+			if category != "" {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("Unknown category %q on %q; using %s", category, title, settings.DefaultCategory))
+			}
+			category = settings.DefaultCategory
+		}
+
+		priority := archived.Priority
+		if !priority.Valid() {
+			priority = models.PriorityNormal
+		}
+
+		points := archived.Points
+		if points < 0 || points > models.MaxTaskPoints {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("Ignored points %d on %q", points, title))
+			points = 0
+		}
+
+		estimate := archived.Estimate
+		if estimate < 0 || estimate > models.MaxEstimateMinutes {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("Ignored estimate of %d minutes on %q", estimate, title))
+			estimate = 0
+		}
+
+		recurrence, err := normalizeRecurrence(archived.Recurrence, archived.DueDate, settings)
+		if err != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("Dropped recurrence on %q: %v", title, err))
+			recurrence = ""
+		}
+
+		creatorID, ok := userIDs[archived.CreatorID]
+		if !ok {
+			creatorID = importerID
+		}
+
+		task := models.Task{
+			Title:       title,
+			Description: archived.Description,
+			Category:    category,
+			Priority:    priority,
+			Points:      points,
+			Estimate:    estimate,
+			DueDate:     archived.DueDate,
+			Recurrence:  recurrence,
+			Completed:   archived.Completed,
+			CreatorID:   creatorID,
+			HouseholdID: householdID,
+			CreatedAt:   archived.CreatedAt,
+
+			RequiresVerification: archived.RequiresVerification,
+		}
+		// Reviewers who were not matched leave the check to the admins
+		if archived.ReviewerID != nil {
+			if reviewerID, ok := userIDs[*archived.ReviewerID]; ok {
+				task.ReviewerID = &reviewerID
+			}
+		}
+		if task.Completed {
+			task.CompletedAt = archived.CompletedAt
+			if task.CompletedAt == nil {
+				task.CompletedAt = &now
+			}
+			if archived.CompletedBy != nil {
+				if completedBy, ok := userIDs[*archived.CompletedBy]; ok {
+					task.CompletedBy = &completedBy
+				}
+			}
+		}
+
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+
+		taskIDs[archived.ID] = task.ID
+		summaries[archived.ID] = len(report.Tasks)
+		report.TasksCreated++
+		report.Tasks = append(report.Tasks, ImportedTask{
+			Title:     task.Title,
+			Category:  task.Category,
+			DueDate:   task.DueDate,
+			Completed: task.Completed,
+			Creator:   names[creatorID],
+			Assignees: []string{},
+		})
+	}
+
+	assigned := make(map[string]bool)
+	for _, archived := range archive.Assignments {
+		taskID, ok := taskIDs[archived.TaskID]
+		if !ok {
+			continue
+		}
+		userID, ok := userIDs[archived.UserID]
+		if !ok || assigned[taskID+"/"+userID] {
+			continue
+		}
+		assigned[taskID+"/"+userID] = true
+
+		if err := tx.Create(&models.TaskAssignment{TaskID: taskID, UserID: userID}).Error; err != nil {
+			return err
+		}
+		report.AssignmentsCreated++
+		summary := &report.Tasks[summaries[archived.TaskID]]
+		summary.Assignees = append(summary.Assignees, names[userID])
+	}
+
+	// Checklist items keep their order; positions are renumbered per task
+	checklists := append([]models.ArchivedChecklist(nil), archive.Checklists...)
+	sort.SliceStable(checklists, func(i, j int) bool { return checklists[i].Position < checklists[j].Position })
+	positions := make(map[string]int)
+	for _, archived := range checklists {
+		taskID, ok := taskIDs[archived.TaskID]
+		if !ok {
+			continue
+		}
+		title := strings.TrimSpace(archived.Title)
+		if title == "" {
+			continue
+		}
+		position := positions[taskID]
+		positions[taskID]++
+		if position >= maxChecklistItems {
+			if position == maxChecklistItems {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("Dropped checklist items beyond %d on %q", maxChecklistItems, report.Tasks[summaries[archived.TaskID]].Title))
+			}
+			continue
+		}
+
+		item := models.TaskChecklistItem{TaskID: taskID, Position: position, Title: title, Done: archived.Done}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		report.ChecklistItemsCreated++
+	}
+
+	linked := make(map[string]bool)
+	for _, archived := range archive.Dependencies {
+		taskID, ok := taskIDs[archived.TaskID]
+		if !ok {
+			continue
+		}
+		blockerID, ok := taskIDs[archived.BlockerID]
+		if !ok || blockerID == taskID || linked[taskID+"/"+blockerID] {
+			continue
+		}
+		linked[taskID+"/"+blockerID] = true
+
+		// Archives can be edited by hand, so cycles are refused as in AddDependency
+		cycle, err := models.DependsOnTransitively(tx, blockerID, taskID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("Skipped a dependency of %q that would create a cycle", report.Tasks[summaries[archived.TaskID]].Title))
+			continue
+		}
+
+		if err := tx.Create(&models.TaskDependency{TaskID: taskID, BlockerID: blockerID, HouseholdID: householdID}).Error; err != nil {
+			return err
+		}
+		report.DependenciesCreated++
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type MembershipController struct {
+	DB *gorm.DB
+}
+
+func NewMembershipController(db *gorm.DB) *MembershipController {
+	return &MembershipController{DB: db}
+}
+
+// HouseholdSummary is one entry of the household switcher
+type HouseholdSummary struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Role         string    `json:"role"`
+	Current      bool      `json:"current"`
+	UnreadCount  int64     `json:"unreadCount"`
+	OverdueCount int64     `json:"overdueCount"`
+	JoinedAt     time.Time `json:"joinedAt"`
+}
+
+// UpdateAvailabilityRequest marks a member as away until a time, or back
+// when awayUntil is null
+type UpdateAvailabilityRequest struct {
+	AwayUntil *time.Time `json:"awayUntil"`
+}
+
+// markHouseholdSeen records that the user has caught up on a household's tasks
+func markHouseholdSeen(db *gorm.DB, userID, householdID string) {
+	db.Model(&models.Membership{}).
+		Where("user_id = ? AND household_id = ?", userID, householdID).
+		Update("last_seen_at", time.Now())
+}
+
+// detachMember removes a user from a household along with their assignments,
+// access tokens, calendar feeds and notifications there. Users who belong to other
+// households are moved to the oldest of them, whose ID is returned; anyone
+// else is signed out everywhere and deleted, and the caller removes their
+// avatar with removeAvatarFiles once the transaction has committed.
+func detachMember(tx *gorm.DB, user models.User, householdID string) (string, error) {
+	householdTasks := tx.Model(&models.Task{}).Select("id").Where("household_id = ?", householdID)
+	if err := tx.Where("user_id = ? AND task_id IN (?)", user.ID, householdTasks).Delete(&models.TaskAssignment{}).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Where("user_id = ? AND household_id = ?", user.ID, householdID).Delete(&models.Membership{}).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND household_id = ? AND revoked_at IS NULL", user.ID, householdID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Model(&models.FeedToken{}).
+		Where("user_id = ? AND household_id = ? AND revoked_at IS NULL", user.ID, householdID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Where("user_id = ? AND household_id = ?", user.ID, householdID).Delete(&models.Notification{}).Error; err != nil {
+		return "", err
+	}
+	if err := models.StopTimers(tx, "user_id = ? AND household_id = ?", user.ID, householdID); err != nil {
+		return "", err
+	}
+	// Tasks they were checking fall back to the admins
+	if err := tx.Model(&models.Task{}).
+		Where("household_id = ? AND reviewer_id = ?", householdID, user.ID).
+		Update("reviewer_id", nil).Error; err != nil {
+		return "", err
+	}
+
+	// Give back the stock held by rewards they were still waiting for
+	var pending []models.RewardRedemption
+	if err := tx.Where("user_id = ? AND household_id = ? AND status = ?", user.ID, householdID, models.RedemptionPending).Find(&pending).Error; err != nil {
+		return "", err
+	}
+	for i := range pending {
+		if err := cancelRedemption(tx, &pending[i]); err != nil {
+			return "", err
+		}
+	}
+
+	var remaining models.Membership
+	if err := tx.Where("user_id = ?", user.ID).Order("created_at").First(&remaining).Error; err == nil {
+		// Move the user's sessions to a household they still belong to.
+		// Their access tokens go stale and the next refresh picks it up.
+		if err := tx.Model(&models.Session{}).Where("user_id = ? AND household_id = ?", user.ID, householdID).
+			Update("household_id", remaining.HouseholdID).Error; err != nil {
+			return "", err
+		}
+		if user.HouseholdID == householdID {
+			if err := tx.Model(&user).Update("household_id", remaining.HouseholdID).Error; err != nil {
+				return "", err
+			}
+		}
+		return remaining.HouseholdID, nil
+	}
+
+	// Sign out all of the user's devices
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.Device{}).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.PairingCode{}).Error; err != nil {
+		return "", err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return "", err
+	}
+	if err := revokeSessions(tx, "left household", "user_id = ?", user.ID); err != nil {
+		return "", err
+	}
+	return "", tx.Delete(&user).Error
+}
+
+// purgeHousehold deletes a household that no longer has members together
+// with its tasks and everything attached to them. It returns the attachment
+// files to remove once the transaction has committed.
+func purgeHousehold(tx *gorm.DB, householdID string) ([]string, error) {
+	householdTasks := tx.Model(&models.Task{}).Select("id").Where("household_id = ?", householdID)
+	if err := tx.Where("task_id IN (?)", householdTasks).Delete(&models.TaskAssignment{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("task_id IN (?)", householdTasks).Delete(&models.TaskChecklistItem{}).Error; err != nil {
+		return nil, err
+	}
+	files, err := deleteAttachments(tx, "household_id = ?", householdID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.TaskDependency{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.Task{}).Error; err != nil {
+		return nil, err
+	}
+	householdTemplates := tx.Model(&models.TaskTemplate{}).Select("id").Where("household_id = ?", householdID)
+	if err := tx.Where("template_id IN (?)", householdTemplates).Delete(&models.TaskTemplateItem{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.TaskTemplate{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.HouseholdSettings{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.TaskTombstone{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.FeedToken{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.AuditEvent{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.Notification{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.TimeEntry{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.PointsEntry{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.RewardRedemption{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("household_id = ?", householdID).Delete(&models.Reward{}).Error; err != nil {
+		return nil, err
+	}
+	return files, tx.Where("id = ?", householdID).Delete(&models.Household{}).Error
+}
+
+// GetHouseholds lists every household the user belongs to with the number of
+// tasks added by others since they last looked and the number overdue
+func (mc *MembershipController) GetHouseholds(c *gin.Context) {
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	var memberships []models.Membership
+	if err := mc.DB.Where("user_id = ?", userID).
+		Preload("Household").
+		Order("created_at").
+		Find(&memberships).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch households"})
+		return
+	}
+
+	now := time.Now()
+	summaries := make([]HouseholdSummary, 0, len(memberships))
+	for _, membership := range memberships {
+		summary := HouseholdSummary{
+			ID:       membership.HouseholdID,
+			Name:     membership.Household.Name,
+			Role:     membership.Role,
+			Current:  membership.HouseholdID == householdID,
+			JoinedAt: membership.CreatedAt,
+		}
+
+		unread := mc.DB.Model(&models.Task{}).
+			Where("household_id = ? AND creator_id != ?", membership.HouseholdID, userID)
+		if membership.LastSeenAt != nil {
+			unread = unread.Where("created_at > ?", *membership.LastSeenAt)
+		}
+		unread.Count(&summary.UnreadCount)
+
+		// Overdue means due before the start of the household's local day
+		settings := models.GetHouseholdSettings(mc.DB, membership.HouseholdID)
+		mc.DB.Model(&models.Task{}).
+			Where("household_id = ? AND completed = ? AND due_date < ?", membership.HouseholdID, false, settings.StartOfDay(now)).
+			Count(&summary.OverdueCount)
+
+		summaries = append(summaries, summary)
+	}
+
+	c.JSON(http.StatusOK, summaries)
+}
+
+// SwitchHousehold moves the current session to another of the user's
+// households and issues tokens scoped to it
+func (mc *MembershipController) SwitchHousehold(c *gin.Context) {
+	householdID := c.Param("id")
+	userID := c.GetString("userID")
+	sessionID := c.GetString("sessionID")
+
+	var membership models.Membership
+	if err := mc.DB.Where("user_id = ? AND household_id = ?", userID, householdID).
+		Preload("Household").
+		First(&membership).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+		return
+	}
+
+	var session models.Session
+	if err := mc.DB.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+		return
+	}
+
+	var tokens authTokens
+	err := mc.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		tokens, err = moveSession(tx, &session, householdID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to switch household"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":        tokens.Token,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+		"household":    membership.Household,
+		"role":         membership.Role,
+	})
+}
+
+// moveSession rescopes a session to another household the user belongs to,
+// makes that household their default and issues tokens for it
+func moveSession(tx *gorm.DB, session *models.Session, householdID string) (authTokens, error) {
+	session.HouseholdID = householdID
+	if err := tx.Model(session).Update("household_id", householdID).Error; err != nil {
+		return authTokens{}, err
+	}
+
+	// Refresh tokens issued for the previous household must not be
+	// redeemable. They are deleted rather than marked used so a client
+	// presenting one does not trip reuse detection and lose the session.
+	if err := tx.Where("session_id = ? AND used_at IS NULL", session.ID).
+		Delete(&models.RefreshToken{}).Error; err != nil {
+		return authTokens{}, err
+	}
+
+	// Remember the choice for the next sign-in
+	if err := tx.Model(&models.User{}).Where("id = ?", session.UserID).Update("household_id", householdID).Error; err != nil {
+		return authTokens{}, err
+	}
+
+	return issueTokens(tx, *session)
+}
+
+// UpdateAvailability sets until when a member is away, so auto-assignment
+// passes them over. Members set their own; admins can set anyone's.
+func (mc *MembershipController) UpdateAvailability(c *gin.Context) {
+	householdID := c.Param("id")
+	memberID := c.Param("userId")
+	userID := c.GetString("userID")
+
+	// Verify user belongs to the requested household
+	if householdID != c.GetString("householdID") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+	if memberID != userID && !models.IsAdmin(mc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change other members' availability"})
+		return
+	}
+
+	var req UpdateAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// A time that has passed means the member is back
+	if req.AwayUntil != nil && !req.AwayUntil.After(time.Now()) {
+		req.AwayUntil = nil
+	}
+
+	result := mc.DB.Model(&models.Membership{}).
+		Where("user_id = ? AND household_id = ?", memberID, householdID).
+		Update("away_until", req.AwayUntil)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update availability"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"userId": memberID, "awayUntil": req.AwayUntil})
+}
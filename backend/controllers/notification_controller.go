@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxNotifications caps how many notifications are listed at once
+const maxNotifications = 100
+
+type NotificationController struct {
+	DB *gorm.DB
+}
+
+func NewNotificationController(db *gorm.DB) *NotificationController {
+	return &NotificationController{DB: db}
+}
+
+type MarkNotificationsReadRequest struct {
+	IDs []string `json:"ids"`
+}
+
+// GetNotifications lists the authenticated user's notifications from every
+// household they belong to, newest first
+func (nc *NotificationController) GetNotifications(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	query := nc.DB.Where("user_id = ?", userID)
+	if unread, _ := strconv.ParseBool(c.Query("unread")); unread {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC").Limit(maxNotifications).Find(&notifications).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+
+	var unreadCount int64
+	nc.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&unreadCount)
+
+	c.JSON(http.StatusOK, gin.H{"notifications": notifications, "unreadCount": unreadCount})
+}
+
+// MarkNotificationsRead marks the given notifications as read, or all of
+// them when no IDs are sent
+func (nc *NotificationController) MarkNotificationsRead(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req MarkNotificationsReadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	query := nc.DB.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID)
+	if len(req.IDs) > 0 {
+		query = query.Where("id IN ?", req.IDs)
+	}
+	result := query.Update("read_at", time.Now())
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": result.RowsAffected})
+}
@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Page sizes for the points ledger
+const (
+	defaultLedgerLimit = 100
+	maxLedgerLimit     = 500
+)
+
+type PointsController struct {
+	DB *gorm.DB
+}
+
+func NewPointsController(db *gorm.DB) *PointsController {
+	return &PointsController{DB: db}
+}
+
+// LeaderboardEntry is one member's standing in a leaderboard window
+type LeaderboardEntry struct {
+	Rank          int    `json:"rank"`
+	UserID        string `json:"userId"`
+	Name          string `json:"name"`
+	Points        int    `json:"points"`
+	Completions   int    `json:"completions"`
+	CurrentStreak int    `json:"currentStreak"`
+	LongestStreak int    `json:"longestStreak"`
+}
+
+// GetLeaderboard ranks the household's members by the points they earned in
+// the current week, the current month or all time. Streaks count the local
+// days in a row on which someone completed at least one task.
+func (pc *PointsController) GetLeaderboard(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	settings := models.GetHouseholdSettings(pc.DB, householdID)
+	now := time.Now()
+	today := settings.StartOfDay(now)
+
+	window := c.DefaultQuery("window", "week")
+	from, ok := windowStart(settings, window, now)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "window must be week, month or all"})
+		return
+	}
+
+	members, err := models.HouseholdMembers(pc.DB, householdID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	// Completions that were later undone do not count in any window
+	var completions []models.PointsEntry
+	if err := pc.DB.Scopes(models.ValidCompletions).
+		Where("household_id = ?", householdID).
+		Order("created_at").
+		Find(&completions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch points"})
+		return
+	}
+
+	entries := make([]LeaderboardEntry, len(members))
+	byUser := make(map[string]*LeaderboardEntry, len(members))
+	days := make(map[string][]time.Time, len(members))
+	for i, member := range members {
+		entries[i] = LeaderboardEntry{UserID: member.ID, Name: member.Name}
+		byUser[member.ID] = &entries[i]
+	}
+	for _, completion := range completions {
+		entry, ok := byUser[completion.UserID]
+		if !ok {
+			continue
+		}
+		days[completion.UserID] = append(days[completion.UserID], settings.StartOfDay(completion.CreatedAt))
+		if from != nil && completion.CreatedAt.Before(*from) {
+			continue
+		}
+		entry.Points += completion.Points
+		entry.Completions++
+	}
+	for i := range entries {
+		entries[i].CurrentStreak, entries[i].LongestStreak = completionStreaks(days[entries[i].UserID], today)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Points != entries[j].Points {
+			return entries[i].Points > entries[j].Points
+		}
+		if entries[i].Completions != entries[j].Completions {
+			return entries[i].Completions > entries[j].Completions
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && entries[i].Points == entries[i-1].Points {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"window": window, "from": from, "entries": entries})
+}
+
+// GetLedger lists the household's points ledger, newest first, optionally
+// for one member
+func (pc *PointsController) GetLedger(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	limit := defaultLedgerLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxLedgerLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+
+	query := pc.DB.Where("household_id = ?", householdID)
+	if userID := c.Query("userId"); userID != "" {
+		if userID == "me" {
+			userID = c.GetString("userID")
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var entries []models.PointsEntry
+	if err := query.Order("created_at DESC").Limit(limit).Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch points"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetBalance returns how many points a member has left to spend, the
+// caller by default
+func (pc *PointsController) GetBalance(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	userID := c.Query("userId")
+	if userID == "" || userID == "me" {
+		userID = c.GetString("userID")
+	}
+	if !models.IsMember(pc.DB, userID, householdID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found in this household"})
+		return
+	}
+
+	balance, err := models.PointsBalance(pc.DB, householdID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch points"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"userId": userID, "balance": balance})
+}
+
+// completionStreaks returns the current and longest runs of consecutive
+// days in days, which must be local midnights in ascending order. The
+// current streak is still alive if the last completion was yesterday.
+func completionStreaks(days []time.Time, today time.Time) (current, longest int) {
+	run := 0
+	var last time.Time
+	for _, day := range days {
+		switch {
+		case run > 0 && day.Equal(last):
+			continue
+		case run > 0 && day.Equal(last.AddDate(0, 0, 1)):
+			run++
+		default:
+			run = 1
+		}
+		last = day
+		if run > longest {
+			longest = run
+		}
+	}
+
+	if run > 0 && (last.Equal(today) || last.Equal(today.AddDate(0, 0, -1))) {
+		current = run
+	}
+	return current, longest
+}
+
+// windowStart is when the current week or month began in the household's
+// time zone, or nil for all time. ok is false for unknown windows.
+func windowStart(settings models.HouseholdSettings, window string, now time.Time) (from *time.Time, ok bool) {
+	switch window {
+	case "week":
+		start := settings.StartOfWeek(now)
+		return &start, true
+	case "month":
+		today := settings.StartOfDay(now)
+		start := today.AddDate(0, 0, 1-today.Day())
+		return &start, true
+	case "all":
+		return nil, true
+	}
+	return nil, false
+}
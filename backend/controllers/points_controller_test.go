@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestCompletionStreaks(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2026, month, d, 0, 0, 0, 0, berlin)
+	}
+	today := day(time.October, 14)
+	// run lists every day of October from one day to another
+	run := func(from, to int) []time.Time {
+		var days []time.Time
+		for d := from; d <= to; d++ {
+			days = append(days, day(time.October, d))
+		}
+		return days
+	}
+
+	tests := []struct {
+		name             string
+		days             []time.Time
+		current, longest int
+	}{
+		{"no completions", nil, 0, 0},
+		{"only today", run(14, 14), 1, 1},
+		{"run ending today", run(10, 14), 5, 5},
+		// Today is not over, so a run ending yesterday is still alive
+		{"run ending yesterday", run(10, 13), 4, 4},
+		{"run ended two days ago", run(10, 12), 0, 3},
+		{"several completions a day", []time.Time{day(time.October, 12), day(time.October, 12), day(time.October, 13), day(time.October, 13), day(time.October, 14)}, 3, 3},
+		{"longer run in the past", append(run(1, 6), run(13, 14)...), 2, 6},
+		{"gap breaks the run", append(run(8, 9), run(11, 14)...), 4, 4},
+		// Local midnights are 23 hours apart when clocks go forward
+		{"across daylight saving", []time.Time{day(time.March, 28), day(time.March, 29), day(time.March, 30)}, 0, 3},
+		{"across new year", []time.Time{time.Date(2025, time.December, 31, 0, 0, 0, 0, berlin), day(time.January, 1)}, 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := completionStreaks(tt.days, today)
+			if current != tt.current || longest != tt.longest {
+				t.Errorf("streaks = %d, %d; want %d, %d", current, longest, tt.current, tt.longest)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultQRSize = 256
+	minQRSize     = 64
+	maxQRSize     = 2048
+	qrBorder      = 4
+)
+
+// writeQRCode encodes content as a QR code and writes it in the format
+// requested by the query string: format=png|svg, size in pixels and
+// ecc=L|M|Q|H for the error correction level.
+func writeQRCode(c *gin.Context, content string) {
+	format := c.DefaultQuery("format", "png")
+	if format != "png" && format != "svg" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be png or svg"})
+		return
+	}
+
+	size := defaultQRSize
+	if raw := c.Query("size"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < minQRSize || parsed > maxQRSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "size must be between 64 and 2048"})
+			return
+		}
+		size = parsed
+	}
+
+	ecl, ok := utils.ParseQRErrorCorrection(c.DefaultQuery("ecc", "M"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ecc must be one of L, M, Q, H"})
+		return
+	}
+
+	qr, err := utils.EncodeQR([]byte(content), ecl)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate QR code"})
+		return
+	}
+
+	// Codes can be refreshed at any time, so never let a stale one be cached
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-QR-Content", content)
+
+	if format == "svg" {
+		c.Data(http.StatusOK, "image/svg+xml", []byte(qr.SVG(size, qrBorder)))
+		return
+	}
+
+	data, err := qr.PNG(size, qrBorder)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate QR code"})
+		return
+	}
+	c.Data(http.StatusOK, "image/png", data)
+}
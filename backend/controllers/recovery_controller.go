@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"household-todo-backend/models"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 12
+)
+
+type RecoveryController struct {
+	DB *gorm.DB
+}
+
+func NewRecoveryController(db *gorm.DB) *RecoveryController {
+	return &RecoveryController{DB: db}
+}
+
+type RecoverAccountRequest struct {
+	Code                string `json:"code" binding:"required"`
+	DeviceID            string `json:"deviceId" binding:"required"`
+	DeviceName          string `json:"deviceName"`
+	RevokeOtherSessions bool   `json:"revokeOtherSessions"`
+}
+
+// GenerateRecoveryCodes replaces the user's recovery codes with a new set.
+// The codes are only returned in this response.
+func (rc *RecoveryController) GenerateRecoveryCodes(c *gin.Context) {
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	codes := make([]string, recoveryCodeCount)
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		for i := range codes {
+			code := utils.GenerateInviteCode(recoveryCodeLength)
+			if err := tx.Create(&models.RecoveryCode{
+				UserID:   userID,
+				CodeHash: utils.HashToken(code),
+			}).Error; err != nil {
+				return err
+			}
+			codes[i] = utils.FormatInviteCode(code)
+		}
+
+		return recordAudit(tx, c, householdID, userID, models.AuditRecoveryCodesGenerated, "")
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"codes": codes})
+}
+
+// GetRecoveryCodeStatus reports how many unused recovery codes the user has left
+func (rc *RecoveryController) GetRecoveryCodeStatus(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var remaining int64
+	if err := rc.DB.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&remaining).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recovery codes"})
+		return
+	}
+
+	var latest models.RecoveryCode
+	var generatedAt *time.Time
+	if err := rc.DB.Where("user_id = ?", userID).Order("created_at DESC").First(&latest).Error; err == nil {
+		generatedAt = &latest.CreatedAt
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"remaining":   remaining,
+		"generatedAt": generatedAt,
+	})
+}
+
+// RecoverAccount redeems a recovery code and signs a new device in as the
+// user the code belongs to
+func (rc *RecoveryController) RecoverAccount(c *gin.Context) {
+	var req RecoverAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := utils.NormalizeInviteCode(req.Code)
+	if !utils.ValidInviteCode(code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recovery code"})
+		return
+	}
+
+	var recovery models.RecoveryCode
+	if err := rc.DB.Where("code_hash = ? AND used_at IS NULL", utils.HashToken(code)).First(&recovery).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recovery code not found or already used"})
+		return
+	}
+
+	var user models.User
+	if err := rc.DB.Where("id = ?", recovery.UserID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var device models.Device
+	if err := rc.DB.Where("device_id = ?", req.DeviceID).First(&device).Error; err == nil && device.UserID != user.ID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Device is already signed in as another user"})
+		return
+	}
+
+	now := time.Now()
+	var tokens authTokens
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		// Use the code atomically so it cannot be redeemed twice
+		result := tx.Model(&models.RecoveryCode{}).
+			Where("id = ? AND used_at IS NULL", recovery.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if req.RevokeOtherSessions {
+			if err := revokeSessions(tx, "account recovered", "user_id = ?", user.ID); err != nil {
+				return err
+			}
+		}
+
+		if device.ID == "" {
+			device = models.Device{
+				UserID:   user.ID,
+				DeviceID: req.DeviceID,
+			}
+		}
+		if req.DeviceName != "" {
+			device.Name = req.DeviceName
+		}
+		device.LastSeen = &now
+		if err := tx.Save(&device).Error; err != nil {
+			return err
+		}
+
+		var remaining int64
+		tx.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&remaining)
+		detail := fmt.Sprintf("device %s; %d recovery codes left", device.DeviceID, remaining)
+		if err := recordAudit(tx, c, user.HouseholdID, user.ID, models.AuditAccountRecovered, detail); err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = createSession(tx, c, user.ID, user.HouseholdID, device.DeviceID)
+		return err
+	})
+	if err == gorm.ErrRecordNotFound {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recovery code not found or already used"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to recover account"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":        tokens.Token,
+		"refreshToken": tokens.RefreshToken,
+		"expiresIn":    tokens.ExpiresIn,
+		"user":         user,
+		"device":       device,
+	})
+}
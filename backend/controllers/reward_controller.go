@@ -0,0 +1,504 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Errors that stop a redemption from going through
+var (
+	errOutOfStock         = errors.New("this reward is out of stock")
+	errInsufficientPoints = errors.New("not enough points to redeem this reward")
+	errRedemptionDecided  = errors.New("this redemption has already been decided")
+)
+
+// Page sizes for redemption history
+const (
+	defaultRedemptionLimit = 100
+	maxRedemptionLimit     = 500
+)
+
+type RewardController struct {
+	DB *gorm.DB
+}
+
+func NewRewardController(db *gorm.DB) *RewardController {
+	return &RewardController{DB: db}
+}
+
+type CreateRewardRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Description  string   `json:"description"`
+	Cost         int      `json:"cost" binding:"required,min=1,max=100000"`
+	Stock        *int     `json:"stock" binding:"omitempty,min=0"`
+	RedeemableBy []string `json:"redeemableBy"`
+}
+
+type UpdateRewardRequest struct {
+	Name           *string   `json:"name"`
+	Description    *string   `json:"description"`
+	Cost           *int      `json:"cost" binding:"omitempty,min=1,max=100000"`
+	Stock          *int      `json:"stock" binding:"omitempty,min=0"`
+	UnlimitedStock bool      `json:"unlimitedStock"`
+	RedeemableBy   *[]string `json:"redeemableBy"`
+}
+
+type DecideRedemptionRequest struct {
+	Note string `json:"note"`
+}
+
+// GetRewards lists the household's rewards catalog, cheapest first
+func (rc *RewardController) GetRewards(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var rewards []models.Reward
+	if err := rc.DB.Where("household_id = ?", householdID).Order("cost, name").Find(&rewards).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch rewards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rewards)
+}
+
+// CreateReward adds a reward to the catalog
+func (rc *RewardController) CreateReward(c *gin.Context) {
+	householdID := c.Param("id")
+	userID := c.GetString("userID")
+	userHouseholdID := c.GetString("householdID")
+
+	// Enforce that the JWT household matches the path household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	if !models.IsAdmin(rc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only household admins can manage rewards"})
+		return
+	}
+
+	var req CreateRewardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	redeemableBy, err := rc.checkRedeemableBy(householdID, req.RedeemableBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reward := models.Reward{
+		HouseholdID:  householdID,
+		Name:         name,
+		Description:  req.Description,
+		Cost:         req.Cost,
+		Stock:        req.Stock,
+		RedeemableBy: redeemableBy,
+		CreatorID:    userID,
+	}
+	if err := rc.DB.Create(&reward).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create reward"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, reward)
+}
+
+// UpdateReward changes a reward. Redemptions already requested keep the
+// cost they were made at.
+func (rc *RewardController) UpdateReward(c *gin.Context) {
+	var req UpdateRewardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reward, ok := rc.findReward(c, true)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
+			return
+		}
+		reward.Name = name
+	}
+	if req.Description != nil {
+		reward.Description = *req.Description
+	}
+	if req.Cost != nil {
+		reward.Cost = *req.Cost
+	}
+	if req.UnlimitedStock {
+		reward.Stock = nil
+	} else if req.Stock != nil {
+		reward.Stock = req.Stock
+	}
+	if req.RedeemableBy != nil {
+		redeemableBy, err := rc.checkRedeemableBy(reward.HouseholdID, *req.RedeemableBy)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		reward.RedeemableBy = redeemableBy
+	}
+
+	if err := rc.DB.Save(reward).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reward"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reward)
+}
+
+// DeleteReward removes a reward from the catalog. Pending redemptions of it
+// are cancelled and refunded; decided ones stay in the history.
+func (rc *RewardController) DeleteReward(c *gin.Context) {
+	reward, ok := rc.findReward(c, true)
+	if !ok {
+		return
+	}
+
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		var pending []models.RewardRedemption
+		if err := tx.Where("reward_id = ? AND status = ?", reward.ID, models.RedemptionPending).Find(&pending).Error; err != nil {
+			return err
+		}
+		for i := range pending {
+			if err := cancelRedemption(tx, &pending[i]); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(reward).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete reward"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reward deleted successfully"})
+}
+
+// RedeemReward asks to spend points on a reward. The points and a unit of
+// stock are taken straight away and held until an admin decides.
+func (rc *RewardController) RedeemReward(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	reward, ok := rc.findReward(c, false)
+	if !ok {
+		return
+	}
+
+	if !reward.CanRedeem(userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This reward is not offered to you"})
+		return
+	}
+
+	var user models.User
+	if err := rc.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Stock is taken and the points debited before the balance is checked,
+	// so the balance already includes this debit. A second redemption by
+	// the same user waits for the first to commit and then sees its debit
+	// too, so the same points cannot be spent twice.
+	redemption := models.RewardRedemption{
+		HouseholdID: reward.HouseholdID,
+		RewardID:    reward.ID,
+		RewardName:  reward.Name,
+		UserID:      userID,
+		Cost:        reward.Cost,
+		Status:      models.RedemptionPending,
+	}
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		if reward.Stock != nil {
+			result := tx.Model(&models.Reward{}).
+				Where("id = ? AND stock > 0", reward.ID).
+				Update("stock", gorm.Expr("stock - 1"))
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errOutOfStock
+			}
+		}
+
+		debit := models.PointsEntry{
+			HouseholdID: reward.HouseholdID,
+			UserID:      userID,
+			Kind:        models.PointsRewardRedeemed,
+			Points:      -reward.Cost,
+			Detail:      reward.Name,
+		}
+		if err := tx.Create(&debit).Error; err != nil {
+			return err
+		}
+
+		balance, err := models.PointsBalance(tx, reward.HouseholdID, userID)
+		if err != nil {
+			return err
+		}
+		if balance < 0 {
+			return errInsufficientPoints
+		}
+
+		redemption.DebitID = debit.ID
+		if err := tx.Create(&redemption).Error; err != nil {
+			return err
+		}
+
+		var admins []string
+		if err := tx.Model(&models.Membership{}).
+			Where("household_id = ? AND role = ? AND user_id <> ?", reward.HouseholdID, models.RoleAdmin, userID).
+			Pluck("user_id", &admins).Error; err != nil {
+			return err
+		}
+		for _, admin := range admins {
+			if err := notifyRedemption(tx, &redemption, admin, models.NotificationRewardRequested,
+				fmt.Sprintf("%s wants to redeem %q for %d points", user.Name, reward.Name, reward.Cost)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == errOutOfStock || err == errInsufficientPoints {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to redeem reward"})
+		return
+	}
+
+	if err := rc.DB.Preload("User").First(&redemption, "id = ?", redemption.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load redemption"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, redemption)
+}
+
+// GetRedemptions lists the household's redemption history, newest first
+func (rc *RewardController) GetRedemptions(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	limit := defaultRedemptionLimit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxRedemptionLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		limit = n
+	}
+
+	query := rc.DB.Where("household_id = ?", householdID)
+	if status := c.Query("status"); status != "" {
+		switch status {
+		case models.RedemptionPending, models.RedemptionApproved, models.RedemptionRejected, models.RedemptionCancelled:
+			query = query.Where("status = ?", status)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown status " + status})
+			return
+		}
+	}
+	if userID := c.Query("userId"); userID != "" {
+		if userID == "me" {
+			userID = c.GetString("userID")
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var redemptions []models.RewardRedemption
+	if err := query.Preload("User").Order("created_at DESC").Limit(limit).Find(&redemptions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch redemptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, redemptions)
+}
+
+// ApproveRedemption hands over a reward. The points were already taken when
+// it was requested.
+func (rc *RewardController) ApproveRedemption(c *gin.Context) {
+	rc.decideRedemption(c, models.RedemptionApproved)
+}
+
+// RejectRedemption turns down a redemption and refunds its points
+func (rc *RewardController) RejectRedemption(c *gin.Context) {
+	rc.decideRedemption(c, models.RedemptionRejected)
+}
+
+// CancelRedemption lets a member withdraw their own pending redemption and
+// get their points back
+func (rc *RewardController) CancelRedemption(c *gin.Context) {
+	rc.decideRedemption(c, models.RedemptionCancelled)
+}
+
+// decideRedemption moves a pending redemption to its final status. Admins
+// approve and reject; only the member who asked can cancel.
+func (rc *RewardController) decideRedemption(c *gin.Context, status string) {
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	var req DecideRedemptionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var redemption models.RewardRedemption
+	if err := rc.DB.Where("id = ? AND household_id = ?", c.Param("id"), householdID).First(&redemption).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Redemption not found"})
+		return
+	}
+
+	if status == models.RedemptionCancelled {
+		if redemption.UserID != userID {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the member who asked can cancel a redemption"})
+			return
+		}
+	} else if !models.IsAdmin(rc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only household admins can approve or reject redemptions"})
+		return
+	}
+
+	err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the first decision on a redemption counts
+		now := time.Now()
+		result := tx.Model(&models.RewardRedemption{}).
+			Where("id = ? AND status = ?", redemption.ID, models.RedemptionPending).
+			Updates(map[string]interface{}{
+				"status":      status,
+				"review_note": strings.TrimSpace(req.Note),
+				"reviewer_id": userID,
+				"reviewed_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRedemptionDecided
+		}
+
+		if status != models.RedemptionApproved {
+			if err := models.ReleaseRedemption(tx, &redemption); err != nil {
+				return err
+			}
+		}
+		if status == models.RedemptionCancelled {
+			return nil
+		}
+		return notifyRedemption(tx, &redemption, redemption.UserID, models.NotificationRedemptionDecided,
+			fmt.Sprintf("Your request for %q was %s", redemption.RewardName, status))
+	})
+	if err == errRedemptionDecided {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update redemption"})
+		return
+	}
+
+	if err := rc.DB.Preload("User").First(&redemption, "id = ?", redemption.ID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load redemption"})
+		return
+	}
+
+	c.JSON(http.StatusOK, redemption)
+}
+
+// findReward loads the reward named in the path from the caller's household.
+// Managing the catalog needs an admin.
+func (rc *RewardController) findReward(c *gin.Context, manage bool) (*models.Reward, bool) {
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	var reward models.Reward
+	if err := rc.DB.Where("id = ? AND household_id = ?", c.Param("id"), householdID).First(&reward).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reward not found"})
+		return nil, false
+	}
+	if manage && !models.IsAdmin(rc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only household admins can manage rewards"})
+		return nil, false
+	}
+	return &reward, true
+}
+
+// checkRedeemableBy makes sure a reward is only offered to household members
+func (rc *RewardController) checkRedeemableBy(householdID string, userIDs []string) ([]string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	members, err := householdMemberIDs(rc.DB, householdID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range userIDs {
+		if !members[id] {
+			return nil, fmt.Errorf("User %s is not a member of this household", id)
+		}
+	}
+	return userIDs, nil
+}
+
+// cancelRedemption withdraws a pending redemption on the member's behalf,
+// for when the reward or the member goes away
+func cancelRedemption(tx *gorm.DB, redemption *models.RewardRedemption) error {
+	if err := tx.Model(redemption).Update("status", models.RedemptionCancelled).Error; err != nil {
+		return err
+	}
+	return models.ReleaseRedemption(tx, redemption)
+}
+
+// notifyRedemption tells a user about a redemption
+func notifyRedemption(tx *gorm.DB, redemption *models.RewardRedemption, userID, kind, message string) error {
+	redemptionID := redemption.ID
+	return tx.Create(&models.Notification{
+		UserID:       userID,
+		HouseholdID:  redemption.HouseholdID,
+		Type:         kind,
+		RedemptionID: &redemptionID,
+		Message:      message,
+	}).Error
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SessionController struct {
+	DB *gorm.DB
+}
+
+func NewSessionController(db *gorm.DB) *SessionController {
+	return &SessionController{DB: db}
+}
+
+// activeSessions scopes a query to sessions that are not revoked and still
+// hold a redeemable refresh token
+func activeSessions(db *gorm.DB) *gorm.DB {
+	live := db.Session(&gorm.Session{NewDB: true}).Model(&models.RefreshToken{}).
+		Select("session_id").
+		Where("used_at IS NULL AND expires_at > ?", time.Now())
+	return db.Where("revoked_at IS NULL AND id IN (?)", live)
+}
+
+// GetSessions lists where the authenticated user is signed in
+func (sc *SessionController) GetSessions(c *gin.Context) {
+	userID := c.GetString("userID")
+	sessionID := c.GetString("sessionID")
+
+	var sessions []models.Session
+	if err := sc.DB.Scopes(activeSessions).Where("user_id = ?", userID).
+		Order("last_used_at DESC").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	var devices []models.Device
+	sc.DB.Where("user_id = ?", userID).Find(&devices)
+	deviceNames := make(map[string]string, len(devices))
+	for _, device := range devices {
+		deviceNames[device.DeviceID] = device.Name
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == sessionID
+		switch {
+		case deviceNames[sessions[i].DeviceID] != "":
+			sessions[i].DeviceLabel = deviceNames[sessions[i].DeviceID]
+		case sessions[i].UserAgent != "":
+			sessions[i].DeviceLabel = sessions[i].UserAgent
+		default:
+			sessions[i].DeviceLabel = sessions[i].DeviceID
+		}
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession signs out one of the user's sessions
+func (sc *SessionController) RevokeSession(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+
+	var session models.Session
+	if err := sc.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).First(&session).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := revokeSessions(sc.DB, "revoked by user", "id = ?", session.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// RevokeOtherSessions signs out every session of the user except the current one
+func (sc *SessionController) RevokeOtherSessions(c *gin.Context) {
+	userID := c.GetString("userID")
+	sessionID := c.GetString("sessionID")
+
+	if err := revokeSessions(sc.DB, "revoked by user", "user_id = ? AND id != ?", userID, sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Other sessions revoked successfully"})
+}
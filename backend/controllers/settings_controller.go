@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// localePattern accepts BCP 47 tags such as "en", "en-US" or "zh-Hant-TW"
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+type SettingsController struct {
+	DB *gorm.DB
+}
+
+func NewSettingsController(db *gorm.DB) *SettingsController {
+	return &SettingsController{DB: db}
+}
+
+type UpdateSettingsRequest struct {
+	Timezone              *string              `json:"timezone"`
+	WeekStart             *int                 `json:"weekStart" binding:"omitempty,min=0,max=6"`
+	Locale                *string              `json:"locale"`
+	DefaultCategory       *models.TaskCategory `json:"defaultCategory"`
+	DefaultReminderOffset *int                 `json:"defaultReminderOffset" binding:"omitempty,min=0,max=10080"`
+	EnforceDependencies   *bool                `json:"enforceDependencies"`
+}
+
+// GetSettings returns the household's settings
+func (sc *SettingsController) GetSettings(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.GetHouseholdSettings(sc.DB, householdID))
+}
+
+// UpdateSettings changes any subset of the household's settings. Only admins
+// can change them since they apply to everyone.
+func (sc *SettingsController) UpdateSettings(c *gin.Context) {
+	householdID := c.Param("id")
+	userID := c.GetString("userID")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	if !models.IsAdmin(sc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only household admins can change settings"})
+		return
+	}
+
+	var req UpdateSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings := models.GetHouseholdSettings(sc.DB, householdID)
+
+	if req.Timezone != nil {
+		// "Local" would mean the server's zone, which is never what a household wants
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" || *req.Timezone == "Local" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown timezone"})
+			return
+		}
+		settings.Timezone = *req.Timezone
+	}
+	if req.WeekStart != nil {
+		settings.WeekStart = time.Weekday(*req.WeekStart)
+	}
+	if req.Locale != nil {
+		if !localePattern.MatchString(*req.Locale) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid locale"})
+			return
+		}
+		settings.Locale = *req.Locale
+	}
+	if req.DefaultCategory != nil {
+		if !req.DefaultCategory.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+			return
+		}
+		settings.DefaultCategory = *req.DefaultCategory
+	}
+	if req.DefaultReminderOffset != nil {
+		settings.DefaultReminderOffset = *req.DefaultReminderOffset
+	}
+	if req.EnforceDependencies != nil {
+		settings.EnforceDependencies = *req.EnforceDependencies
+	}
+
+	if err := sc.DB.Save(&settings).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
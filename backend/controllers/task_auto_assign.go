@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type AutoAssignRequest struct {
+	Window string `json:"window"` // As for the fairness report
+	Weight string `json:"weight"`
+}
+
+// AutoAssignCandidate is a member auto-assignment considered, with their load
+// without the task
+type AutoAssignCandidate struct {
+	UserID    string  `json:"userId"`
+	Name      string  `json:"name"`
+	Load      float64 `json:"load"`
+	Available bool    `json:"available"`
+	Reason    string  `json:"reason,omitempty"` // Why an unavailable member was passed over
+}
+
+// AutoAssignTask gives a task to the available member with the lightest
+// load, replacing its assignees. Members who are away until after the task
+// is due, or away now for tasks without a due date, are passed over, as is
+// the task's reviewer.
+func (tc *TaskController) AutoAssignTask(c *gin.Context) {
+	householdID := c.GetString("householdID")
+
+	var task models.Task
+	if err := tc.DB.Where("id = ? AND household_id = ?", c.Param("id"), householdID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if task.Completed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task is already done"})
+		return
+	}
+
+	var req AutoAssignRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	window, weight, ok := workloadOptions(c, req.Window, req.Weight)
+	if !ok {
+		return
+	}
+
+	// The task itself does not count towards anyone's load
+	workload, err := householdWorkload(tc.DB, householdID, window, weight, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute workload"})
+		return
+	}
+
+	// Availability is judged at the due date, or now if that has passed
+	at := time.Now()
+	if task.DueDate != nil && task.DueDate.After(at) {
+		at = *task.DueDate
+	}
+
+	candidates := make([]AutoAssignCandidate, 0, len(workload.Members))
+	var chosen *MemberWorkload
+	for i := range workload.Members {
+		member := &workload.Members[i]
+		candidate := AutoAssignCandidate{
+			UserID:    member.UserID,
+			Name:      member.Name,
+			Load:      round3(member.CompletedEffort + member.AssignedEffort),
+			Available: true,
+		}
+		switch {
+		case member.AwayUntil != nil && member.AwayUntil.After(at):
+			candidate.Available, candidate.Reason = false, "away"
+		case task.RequiresVerification && task.ReviewerID != nil && *task.ReviewerID == member.UserID:
+			candidate.Available, candidate.Reason = false, "reviewer"
+		}
+		candidates = append(candidates, candidate)
+
+		if candidate.Available && (chosen == nil || lighterLoad(member, chosen)) {
+			chosen = member
+		}
+	}
+	if chosen == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Nobody is available to take this task", "candidates": candidates})
+		return
+	}
+
+	if err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", task.ID).Delete(&models.TaskAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.TaskAssignment{TaskID: task.ID, UserID: chosen.UserID}).Error
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign task"})
+		return
+	}
+	touchTask(tc.DB, &task)
+
+	// Reload task with relationships
+	if err := tc.loadTask(&task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"task": task, "assigneeId": chosen.UserID, "candidates": candidates})
+}
+
+// lighterLoad reports whether a should take a task before b: the smaller
+// load first, then fewer open tasks, then the name
+func lighterLoad(a, b *MemberWorkload) bool {
+	loadA, loadB := a.CompletedEffort+a.AssignedEffort, b.CompletedEffort+b.AssignedEffort
+	if loadA != loadB {
+		return loadA < loadB
+	}
+	if a.AssignedTasks != b.AssignedTasks {
+		return a.AssignedTasks < b.AssignedTasks
+	}
+	return a.Name < b.Name
+}
@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"household-todo-backend/models"
+	"household-todo-backend/utils"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -22,22 +27,45 @@ type CreateTaskRequest struct {
 	Title       string              `json:"title" binding:"required"`
 	Description string              `json:"description"`
 	Category    models.TaskCategory `json:"category"`
+	Priority    models.TaskPriority `json:"priority"`
+	Points      int                 `json:"points" binding:"min=0,max=1000"`
+	Estimate    int                 `json:"estimateMinutes" binding:"min=0,max=10080"`
 	DueDate     *time.Time          `json:"dueDate"`
+	Recurrence  string              `json:"recurrence"`
 	AssignedTo  []string            `json:"assignedTo"`
+	Checklist   []string            `json:"checklist"`
+
+	RequiresVerification bool    `json:"requiresVerification"`
+	ReviewerID           *string `json:"reviewerId"`
 }
 
 type UpdateTaskRequest struct {
 	Title       string              `json:"title"`
 	Description string              `json:"description"`
 	Category    models.TaskCategory `json:"category"`
+	Priority    models.TaskPriority `json:"priority"`
+	Points      *int                `json:"points" binding:"omitempty,min=0,max=1000"`
+	Estimate    *int                `json:"estimateMinutes" binding:"omitempty,min=0,max=10080"`
 	DueDate     *time.Time          `json:"dueDate"`
+	Recurrence  *string             `json:"recurrence"`
 	AssignedTo  []string            `json:"assignedTo"`
+
+	RequiresVerification *bool   `json:"requiresVerification"`
+	ReviewerID           *string `json:"reviewerId"` // "" for any admin
 }
 
 type AssignTaskRequest struct {
 	UserIDs []string `json:"userIds" binding:"required"`
 }
 
+type UpdateChecklistItemRequest struct {
+	Title *string `json:"title"`
+	Done  *bool   `json:"done"`
+}
+
+// maxChecklistItems caps how many steps a task's checklist can have
+const maxChecklistItems = 50
+
 // GetHouseholdTasks retrieves all tasks for a household
 func (tc *TaskController) GetHouseholdTasks(c *gin.Context) {
 	householdID := c.Param("id")
@@ -49,14 +77,29 @@ func (tc *TaskController) GetHouseholdTasks(c *gin.Context) {
 		return
 	}
 
+	settings := models.GetHouseholdSettings(tc.DB, householdID)
+	query, err := filterTasks(c, tc.DB.Where("household_id = ?", householdID), settings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var tasks []models.Task
-	if err := tc.DB.Where("household_id = ?", householdID).
+	if err := query.
 		Preload("Creator").
 		Preload("Assignments.User").
+		Preload("Checklist", orderChecklist).
 		Find(&tasks).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
 		return
 	}
+	decorateTasks(settings, tasks)
+	if err := models.LoadDependencies(tc.DB, tasks); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
+	}
+
+	markHouseholdSeen(tc.DB, c.GetString("userID"), householdID)
 
 	c.JSON(http.StatusOK, tasks)
 }
@@ -86,20 +129,65 @@ func (tc *TaskController) CreateTask(c *gin.Context) {
 		return
 	}
 
-	// Verify user exists and belongs to the household
-	var creator models.User
-	if err := tc.DB.Where("id = ? AND household_id = ?", userID, householdID).First(&creator).Error; err != nil {
+	// Verify user belongs to the household
+	if !models.IsMember(tc.DB, userID, householdID) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "User not authorized for this household"})
 		return
 	}
 
+	// Points are credited to whoever completes the task, so only admins set them
+	if req.Points != 0 && !models.IsAdmin(tc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can set points"})
+		return
+	}
+
+	settings := models.GetHouseholdSettings(tc.DB, householdID)
+
+	// Tasks without a category use the household's default
+	if req.Category == "" {
+		req.Category = settings.DefaultCategory
+	}
+
+	if req.Priority == "" {
+		req.Priority = models.PriorityNormal
+	}
+	if !req.Priority.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "priority must be LOW, NORMAL or HIGH"})
+		return
+	}
+
+	recurrence, err := normalizeRecurrence(req.Recurrence, req.DueDate, settings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	checklist, err := cleanChecklist(req.Checklist)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reviewerID, err := tc.checkReviewer(householdID, req.ReviewerID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	task := models.Task{
 		Title:       req.Title,
 		Description: req.Description,
 		Category:    req.Category,
+		Priority:    req.Priority,
+		Points:      req.Points,
+		Estimate:    req.Estimate,
 		DueDate:     req.DueDate,
+		Recurrence:  recurrence,
 		CreatorID:   userID,
 		HouseholdID: householdID,
+
+		RequiresVerification: req.RequiresVerification,
+		ReviewerID:           reviewerID,
 	}
 
 	if err := tc.DB.Create(&task).Error; err != nil {
@@ -118,8 +206,13 @@ func (tc *TaskController) CreateTask(c *gin.Context) {
 		}
 	}
 
+	if err := models.CreateChecklist(tc.DB, task.ID, checklist); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create checklist"})
+		return
+	}
+
 	// Reload task with relationships
-	if err := tc.DB.Preload("Creator").Preload("Assignments.User").Where("id = ?", task.ID).First(&task).Error; err != nil {
+	if err := tc.loadTask(&task); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
 		return
 	}
@@ -154,9 +247,64 @@ func (tc *TaskController) UpdateTask(c *gin.Context) {
 	if req.Category != "" {
 		task.Category = req.Category
 	}
+	if req.Priority != "" {
+		if !req.Priority.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "priority must be LOW, NORMAL or HIGH"})
+			return
+		}
+		task.Priority = req.Priority
+	}
+	if req.Estimate != nil {
+		task.Estimate = *req.Estimate
+	}
+	if req.Points != nil && *req.Points != task.Points {
+		if !models.IsAdmin(tc.DB, c.GetString("userID"), householdID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change points"})
+			return
+		}
+		// The points were credited when the task was completed
+		if task.Completed {
+			c.JSON(http.StatusConflict, gin.H{"error": "Points of a completed task cannot be changed"})
+			return
+		}
+		task.Points = *req.Points
+	}
 	if req.DueDate != nil {
 		task.DueDate = req.DueDate
 	}
+	if req.Recurrence != nil {
+		task.Recurrence = *req.Recurrence
+	}
+	if task.Recurrence != "" {
+		recurrence, err := normalizeRecurrence(task.Recurrence, task.DueDate, models.GetHouseholdSettings(tc.DB, householdID))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		task.Recurrence = recurrence
+	}
+
+	// Whoever is being checked cannot change who checks them or switch it off
+	if req.RequiresVerification != nil || req.ReviewerID != nil {
+		if task.RequiresVerification && !canReviewTask(tc.DB, &task, c.GetString("userID")) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the task's reviewer can change how it is checked"})
+			return
+		}
+	}
+	if req.ReviewerID != nil {
+		reviewerID, err := tc.checkReviewer(householdID, req.ReviewerID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		task.ReviewerID = reviewerID
+	}
+	if req.RequiresVerification != nil {
+		task.RequiresVerification = *req.RequiresVerification
+		if !task.RequiresVerification && task.ReviewStatus == models.ReviewPending {
+			clearReview(&task)
+		}
+	}
 
 	if err := tc.DB.Save(&task).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
@@ -179,7 +327,7 @@ func (tc *TaskController) UpdateTask(c *gin.Context) {
 	}
 
 	// Reload task with relationships
-	if err := tc.DB.Preload("Creator").Preload("Assignments.User").Where("id = ?", task.ID).First(&task).Error; err != nil {
+	if err := tc.loadTask(&task); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
 		return
 	}
@@ -198,10 +346,16 @@ func (tc *TaskController) DeleteTask(c *gin.Context) {
 		return
 	}
 
-	if err := tc.DB.Delete(&task).Error; err != nil {
+	var files []string
+	if err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		files, err = deleteTask(tx, &task, c.GetString("userID"))
+		return err
+	}); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
 		return
 	}
+	removeFiles(files)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Task deleted successfully"})
 }
@@ -218,24 +372,50 @@ func (tc *TaskController) ToggleTaskCompletion(c *gin.Context) {
 		return
 	}
 
+	// Tasks that need checking go to their reviewer instead of being
+	// completed
+	if !task.Completed && task.RequiresVerification && !canReviewTask(tc.DB, &task, userID) {
+		tc.toggleReview(c, &task)
+		return
+	}
+
 	task.Completed = !task.Completed
 	now := time.Now()
 
+	// Households can refuse to complete tasks that are still waiting on others
+	if task.Completed && refuseBlocked(c, tc.DB, &task) {
+		return
+	}
+
 	if task.Completed {
 		task.CompletedAt = &now
 		task.CompletedBy = &userID
+		if task.RequiresVerification {
+			signOff(&task, userID, now)
+		}
 	} else {
 		task.CompletedAt = nil
 		task.CompletedBy = nil
+		clearReview(&task)
 	}
 
-	if err := tc.DB.Save(&task).Error; err != nil {
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		if task.Completed {
+			return saveCompletion(tx, &task, userID)
+		}
+		return saveReopening(tx, &task)
+	})
+	if err == errCompletionChanged {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task was changed by someone else; reload it and try again"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
 		return
 	}
 
 	// Reload task with relationships
-	if err := tc.DB.Preload("Creator").Preload("Assignments.User").Where("id = ?", task.ID).First(&task).Error; err != nil {
+	if err := tc.loadTask(&task); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
 		return
 	}
@@ -274,9 +454,10 @@ func (tc *TaskController) AssignTask(c *gin.Context) {
 			tc.DB.Create(&assignment)
 		}
 	}
+	touchTask(tc.DB, &task)
 
 	// Reload task with relationships
-	if err := tc.DB.Preload("Creator").Preload("Assignments.User").Where("id = ?", task.ID).First(&task).Error; err != nil {
+	if err := tc.loadTask(&task); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
 		return
 	}
@@ -300,12 +481,393 @@ func (tc *TaskController) UnassignTask(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unassign task"})
 		return
 	}
+	touchTask(tc.DB, &task)
+
+	// Reload task with relationships
+	if err := tc.loadTask(&task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// UpdateChecklistItem ticks off or renames one step of a task's checklist
+func (tc *TaskController) UpdateChecklistItem(c *gin.Context) {
+	taskID := c.Param("id")
+	itemID := c.Param("itemId")
+	householdID := c.GetString("householdID")
+
+	var req UpdateChecklistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var task models.Task
+	if err := tc.DB.Where("id = ? AND household_id = ?", taskID, householdID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	var item models.TaskChecklistItem
+	if err := tc.DB.Where("id = ? AND task_id = ?", itemID, task.ID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Checklist item not found"})
+		return
+	}
+
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "title cannot be empty"})
+			return
+		}
+		item.Title = title
+	}
+	if req.Done != nil {
+		item.Done = *req.Done
+	}
+
+	if err := tc.DB.Save(&item).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist item"})
+		return
+	}
+	touchTask(tc.DB, &task)
 
 	// Reload task with relationships
-	if err := tc.DB.Preload("Creator").Preload("Assignments.User").Where("id = ?", task.ID).First(&task).Error; err != nil {
+	if err := tc.loadTask(&task); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
 		return
 	}
 
 	c.JSON(http.StatusOK, task)
 }
+
+// filterTasks narrows a task query using the task list's query parameters:
+// status (open|completed), category (comma-separated), assignee (a user ID,
+// "me" or "none"), dueFrom/dueTo (dates in the household's time zone or
+// RFC 3339 timestamps), overdue=true, blocked=true|false and q (text search)
+func filterTasks(c *gin.Context, query *gorm.DB, settings models.HouseholdSettings) (*gorm.DB, error) {
+	switch c.Query("status") {
+	case "", "all":
+	case "open":
+		query = query.Where("completed = ?", false)
+	case "completed":
+		query = query.Where("completed = ?", true)
+	default:
+		return nil, errors.New("status must be open, completed or all")
+	}
+
+	if categories := c.Query("category"); categories != "" {
+		var list []models.TaskCategory
+		for _, category := range strings.Split(categories, ",") {
+			category := models.TaskCategory(strings.ToUpper(strings.TrimSpace(category)))
+			if !category.Valid() {
+				return nil, fmt.Errorf("unknown category %q", category)
+			}
+			list = append(list, category)
+		}
+		query = query.Where("category IN ?", list)
+	}
+
+	assigned := query.Session(&gorm.Session{NewDB: true}).Model(&models.TaskAssignment{}).Select("task_id")
+	switch assignee := c.Query("assignee"); assignee {
+	case "":
+	case "none":
+		query = query.Where("id NOT IN (?)", assigned)
+	default:
+		if assignee == "me" {
+			assignee = c.GetString("userID")
+		}
+		query = query.Where("id IN (?)", assigned.Where("user_id = ?", assignee))
+	}
+
+	if value := c.Query("dueFrom"); value != "" {
+		from, err := parseDueBound(value, settings, false)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("due_date >= ?", from)
+	}
+	if value := c.Query("dueTo"); value != "" {
+		to, err := parseDueBound(value, settings, true)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("due_date < ?", to)
+	}
+
+	if overdue, _ := strconv.ParseBool(c.Query("overdue")); overdue {
+		query = query.Where("completed = ? AND due_date < ?", false, settings.StartOfDay(time.Now()))
+	}
+
+	switch review := c.Query("review"); review {
+	case "":
+	case models.ReviewPending, models.ReviewApproved, models.ReviewRejected:
+		query = query.Where("review_status = ?", review)
+	case "mine":
+		// Submissions waiting for the caller to check them
+		userID := c.GetString("userID")
+		reviewable := query.Session(&gorm.Session{NewDB: true}).Where("reviewer_id = ?", userID)
+		if models.IsAdmin(query.Session(&gorm.Session{NewDB: true}), userID, settings.HouseholdID) {
+			reviewable = reviewable.Or("reviewer_id IS NULL")
+		}
+		query = query.Where("review_status = ?", models.ReviewPending).Where(reviewable)
+	default:
+		return nil, errors.New("review must be pending, approved, rejected or mine")
+	}
+
+	if value := c.Query("blocked"); value != "" {
+		blocked, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("blocked must be true or false")
+		}
+		openBlockers := query.Session(&gorm.Session{NewDB: true}).Model(&models.TaskDependency{}).
+			Select("task_dependencies.task_id").
+			Joins("JOIN tasks AS blockers ON blockers.id = task_dependencies.blocker_id").
+			Where("blockers.completed = ?", false)
+		if blocked {
+			query = query.Where("completed = ? AND id IN (?)", false, openBlockers)
+		} else {
+			query = query.Where("(completed = ? OR id NOT IN (?))", true, openBlockers)
+		}
+	}
+
+	if q := strings.TrimSpace(c.Query("q")); q != "" {
+		pattern := "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(q) + "%"
+		query = query.Where(`(title LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+
+	return query, nil
+}
+
+// parseDueBound reads a dueFrom/dueTo value. A plain date means the start of
+// that day in the household's time zone, or the start of the next day for
+// an inclusive upper bound.
+func parseDueBound(value string, settings models.HouseholdSettings, upper bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, settings.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	if upper {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+// normalizeRecurrence validates a recurrence rule and returns it in
+// canonical form. Recurring tasks need a due date to repeat from.
+func normalizeRecurrence(value string, dueDate *time.Time, settings models.HouseholdSettings) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", nil
+	}
+	if dueDate == nil {
+		return "", errors.New("recurring tasks need a due date")
+	}
+	rule, err := utils.ParseRRule(value, settings.Location())
+	if err != nil {
+		return "", fmt.Errorf("invalid recurrence: %w", err)
+	}
+	return rule.String(), nil
+}
+
+// scheduleNextOccurrence creates the next task of a recurring series when
+// one is completed. The series continues on the new task, so the completed
+// task stops recurring.
+func scheduleNextOccurrence(tx *gorm.DB, task *models.Task) error {
+	settings := models.GetHouseholdSettings(tx, task.HouseholdID)
+	rule, err := utils.ParseRRule(task.Recurrence, settings.Location())
+	if err != nil || task.DueDate == nil {
+		return err
+	}
+
+	next, ok := rule.Next(task.DueDate.In(settings.Location()), settings.WeekStart)
+	task.Recurrence = ""
+	if !ok {
+		return nil
+	}
+	if rule.Count > 0 {
+		rule.Count--
+	}
+
+	successor := models.Task{
+		Title:       task.Title,
+		Description: task.Description,
+		Category:    task.Category,
+		Priority:    task.Priority,
+		Points:      task.Points,
+		Estimate:    task.Estimate,
+		DueDate:     &next,
+		Recurrence:  rule.String(),
+		CreatorID:   task.CreatorID,
+		HouseholdID: task.HouseholdID,
+
+		RequiresVerification: task.RequiresVerification,
+		ReviewerID:           task.ReviewerID,
+	}
+	if err := tx.Create(&successor).Error; err != nil {
+		return err
+	}
+
+	var assignments []models.TaskAssignment
+	if err := tx.Where("task_id = ?", task.ID).Find(&assignments).Error; err != nil {
+		return err
+	}
+	for _, assignment := range assignments {
+		if err := tx.Create(&models.TaskAssignment{TaskID: successor.ID, UserID: assignment.UserID}).Error; err != nil {
+			return err
+		}
+	}
+
+	// The next occurrence starts with its checklist unticked
+	var checklist []models.TaskChecklistItem
+	if err := tx.Where("task_id = ?", task.ID).Order("position").Find(&checklist).Error; err != nil {
+		return err
+	}
+	titles := make([]string, len(checklist))
+	for i, item := range checklist {
+		titles[i] = item.Title
+	}
+	return models.CreateChecklist(tx, successor.ID, titles)
+}
+
+// errCompletionChanged is returned when another request completed or
+// reopened a task first
+var errCompletionChanged = errors.New("task completion changed concurrently")
+
+// markCompleted flips a task's completed flag, but only while it still has
+// the opposite value, so of two concurrent requests only one goes on to
+// credit or reverse the points
+func markCompleted(tx *gorm.DB, taskID string, completed bool) error {
+	result := tx.Model(&models.Task{}).
+		Where("id = ? AND completed = ?", taskID, !completed).
+		Update("completed", completed)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errCompletionChanged
+	}
+	return nil
+}
+
+// saveCompletion saves a task that has just been completed. A recurring
+// task gets its next occurrence, the points go to whoever completed it and
+// tasks waiting on it are released. It fails with errCompletionChanged if the
+// task was completed in the meantime.
+func saveCompletion(tx *gorm.DB, task *models.Task, actorID string) error {
+	if err := markCompleted(tx, task.ID, true); err != nil {
+		return err
+	}
+	if task.Recurrence != "" {
+		if err := scheduleNextOccurrence(tx, task); err != nil {
+			return err
+		}
+	}
+	if err := tx.Save(task).Error; err != nil {
+		return err
+	}
+	if err := models.CreditTaskCompletion(tx, task, *task.CompletedBy); err != nil {
+		return err
+	}
+	// Nobody is still working on a task that is done
+	if err := models.StopTimers(tx, "task_id = ?", task.ID); err != nil {
+		return err
+	}
+	return releaseDependents(tx, task, actorID)
+}
+
+// saveReopening saves a completed task that has been marked as not done and
+// takes back the points for it. It fails with errCompletionChanged if the
+// task was reopened in the meantime.
+func saveReopening(tx *gorm.DB, task *models.Task) error {
+	if err := markCompleted(tx, task.ID, false); err != nil {
+		return err
+	}
+	if err := tx.Save(task).Error; err != nil {
+		return err
+	}
+	return models.ReverseTaskCompletion(tx, task)
+}
+
+// refuseBlocked answers with a conflict and returns true when the household
+// refuses to complete tasks that still wait on open ones and this is one
+func refuseBlocked(c *gin.Context, db *gorm.DB, task *models.Task) bool {
+	if !models.GetHouseholdSettings(db, task.HouseholdID).EnforceDependencies {
+		return false
+	}
+	blockers, err := models.OpenBlockers(db, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check dependencies"})
+		return true
+	}
+	if len(blockers) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task is waiting on tasks that are not done", "blockedBy": blockers})
+		return true
+	}
+	return false
+}
+
+// orderChecklist sorts preloaded checklist items by position
+func orderChecklist(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+// cleanChecklist trims checklist titles and drops blank ones
+func cleanChecklist(items []string) ([]string, error) {
+	var titles []string
+	for _, item := range items {
+		if title := strings.TrimSpace(item); title != "" {
+			titles = append(titles, title)
+		}
+	}
+	if len(titles) > maxChecklistItems {
+		return nil, fmt.Errorf("checklists can have at most %d items", maxChecklistItems)
+	}
+	return titles, nil
+}
+
+// touchTask bumps a task's updated time after its assignments change, so
+// CalDAV clients and feeds notice the new attendees
+func touchTask(db *gorm.DB, task *models.Task) {
+	db.Model(task).Update("updated_at", time.Now())
+}
+
+// loadTask reloads a task with its relationships and computed fields
+func (tc *TaskController) loadTask(task *models.Task) error {
+	if err := tc.DB.Preload("Creator").
+		Preload("Assignments.User").
+		Preload("Checklist", orderChecklist).
+		Where("id = ?", task.ID).
+		First(task).Error; err != nil {
+		return err
+	}
+	decorateTask(models.GetHouseholdSettings(tc.DB, task.HouseholdID), time.Now(), task)
+
+	tasks := []models.Task{*task}
+	if err := models.LoadDependencies(tc.DB, tasks); err != nil {
+		return err
+	}
+	*task = tasks[0]
+	return nil
+}
+
+// decorateTasks fills in fields that depend on the household's local day
+func decorateTasks(settings models.HouseholdSettings, tasks []models.Task) {
+	now := time.Now()
+	for i := range tasks {
+		decorateTask(settings, now, &tasks[i])
+	}
+}
+
+func decorateTask(settings models.HouseholdSettings, now time.Time, task *models.Task) {
+	if task.DueDate == nil || task.Completed {
+		return
+	}
+	today := settings.StartOfDay(now)
+	task.Overdue = settings.IsOverdue(*task.DueDate, now)
+	task.DueToday = !task.DueDate.Before(today) && task.DueDate.Before(today.AddDate(0, 0, 1))
+}
@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errDependencyCycle rejects a link that would leave tasks waiting on each
+// other
+var errDependencyCycle = errors.New("the blocking task already depends on this task")
+
+type AddDependencyRequest struct {
+	BlockerID string `json:"blockerId" binding:"required"`
+}
+
+// AddDependency makes a task wait on another task in the same household
+func (tc *TaskController) AddDependency(c *gin.Context) {
+	taskID := c.Param("id")
+	householdID := c.GetString("householdID")
+
+	var req AddDependencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var task models.Task
+	if err := tc.DB.Where("id = ? AND household_id = ?", taskID, householdID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	if req.BlockerID == task.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A task cannot depend on itself"})
+		return
+	}
+
+	var blocker models.Task
+	if err := tc.DB.Where("id = ? AND household_id = ?", req.BlockerID, householdID).First(&blocker).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Blocking task not found"})
+		return
+	}
+
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.TaskDependency{}).Where("task_id = ? AND blocker_id = ?", task.ID, blocker.ID).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		cycle, err := models.DependsOnTransitively(tx, blocker.ID, task.ID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return errDependencyCycle
+		}
+
+		return tx.Create(&models.TaskDependency{
+			TaskID:      task.ID,
+			BlockerID:   blocker.ID,
+			HouseholdID: householdID,
+		}).Error
+	})
+	if err == errDependencyCycle {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add dependency"})
+		return
+	}
+
+	// Reload task with relationships
+	if err := tc.loadTask(&task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// RemoveDependency stops a task waiting on another
+func (tc *TaskController) RemoveDependency(c *gin.Context) {
+	taskID := c.Param("id")
+	blockerID := c.Param("blockerId")
+	householdID := c.GetString("householdID")
+
+	var task models.Task
+	if err := tc.DB.Where("id = ? AND household_id = ?", taskID, householdID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		wasBlocked, err := models.OpenBlockers(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("task_id = ? AND blocker_id = ?", task.ID, blockerID).Delete(&models.TaskDependency{}).Error; err != nil {
+			return err
+		}
+		if len(wasBlocked) == 0 {
+			return nil
+		}
+		return notifyUnblocked(tx, []string{task.ID}, c.GetString("userID"))
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove dependency"})
+		return
+	}
+
+	// Reload task with relationships
+	if err := tc.loadTask(&task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// waitingOn returns the IDs of the tasks that wait on a task
+func waitingOn(tx *gorm.DB, taskID string) ([]string, error) {
+	var ids []string
+	err := tx.Model(&models.TaskDependency{}).Where("blocker_id = ?", taskID).Pluck("task_id", &ids).Error
+	return ids, err
+}
+
+// notifyUnblocked tells the people working on each of the given tasks that
+// it no longer waits on anything. Tasks that are completed or still blocked
+// are skipped. Assignees are told, or everyone in the household for
+// unassigned tasks, except whoever caused the change.
+func notifyUnblocked(tx *gorm.DB, taskIDs []string, actorID string) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	var tasks []models.Task
+	if err := tx.Where("id IN ? AND completed = ?", taskIDs, false).Preload("Assignments").Find(&tasks).Error; err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		open, err := models.OpenBlockers(tx, task.ID)
+		if err != nil {
+			return err
+		}
+		if len(open) > 0 {
+			continue
+		}
+
+		var recipients []string
+		for _, assignment := range task.Assignments {
+			recipients = append(recipients, assignment.UserID)
+		}
+		if len(recipients) == 0 {
+			if err := tx.Model(&models.Membership{}).Where("household_id = ?", task.HouseholdID).Pluck("user_id", &recipients).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, userID := range recipients {
+			if userID == actorID {
+				continue
+			}
+			if err := notifyTask(tx, &task, userID, models.NotificationTaskUnblocked,
+				fmt.Sprintf("%q is ready to start: everything it was waiting on is done", task.Title)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// releaseDependents lets people know about tasks that were only waiting on
+// a task that has just been completed
+func releaseDependents(tx *gorm.DB, task *models.Task, actorID string) error {
+	ids, err := waitingOn(tx, task.ID)
+	if err != nil {
+		return err
+	}
+	return notifyUnblocked(tx, ids, actorID)
+}
+
+// deleteTask deletes a task and returns the attachment files to remove once
+// the transaction has committed. Deleting an open task that others wait on
+// unblocks them just like completing it.
+func deleteTask(tx *gorm.DB, task *models.Task, actorID string) ([]string, error) {
+	ids, err := waitingOn(tx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	files, err := deleteAttachments(tx, "task_id = ?", task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := models.StopTimers(tx, "task_id = ?", task.ID); err != nil {
+		return nil, err
+	}
+	if err := models.DeleteTask(tx, task); err != nil {
+		return nil, err
+	}
+	if task.Completed {
+		return files, nil
+	}
+	return files, notifyUnblocked(tx, ids, actorID)
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// taskCSVHeader matches the columns POST /api/households/import reads
+var taskCSVHeader = []string{"id", "title", "description", "category", "priority", "dueDate", "recurrence", "completed", "completedAt", "assignees", "creator", "createdAt"}
+
+// ExportTasks writes the household's tasks as CSV or iCalendar, narrowed by
+// the same filters as the task list
+func (tc *TaskController) ExportTasks(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	component := calendarTodo
+	switch format {
+	case "csv":
+	case "ics":
+		switch c.DefaultQuery("component", "todo") {
+		case "todo":
+		case "event":
+			component = calendarEvent
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "component must be todo or event"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or ics"})
+		return
+	}
+
+	var household models.Household
+	if err := tc.DB.Where("id = ?", householdID).First(&household).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Household not found"})
+		return
+	}
+
+	settings := models.GetHouseholdSettings(tc.DB, householdID)
+	query, err := filterTasks(c, tc.DB.Where("household_id = ?", householdID), settings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var tasks []models.Task
+	if err := query.
+		Preload("Creator").
+		Preload("Assignments.User").
+		Order("due_date IS NULL, due_date, created_at").
+		Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tasks"})
+		return
+	}
+
+	filename := fmt.Sprintf("tasks-%s.%s", time.Now().In(settings.Location()).Format("2006-01-02"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+
+	if format == "ics" {
+		c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(taskCalendar(household.Name, tasks, settings, component)))
+		return
+	}
+
+	data, err := tasksCSV(tasks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export tasks"})
+		return
+	}
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// tasksCSV renders tasks as CSV. Tasks need Creator and Assignments.User
+// preloaded.
+func tasksCSV(tasks []models.Task) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(taskCSVHeader); err != nil {
+		return nil, err
+	}
+
+	for _, task := range tasks {
+		assignees := make([]string, 0, len(task.Assignments))
+		for _, assignment := range task.Assignments {
+			assignees = append(assignees, assignment.User.Name)
+		}
+
+		completed := "false"
+		if task.Completed {
+			completed = "true"
+		}
+
+		if err := w.Write([]string{
+			task.ID,
+			csvCell(task.Title),
+			csvCell(task.Description),
+			string(task.Category),
+			string(task.Priority),
+			csvTime(task.DueDate),
+			task.Recurrence,
+			completed,
+			csvTime(task.CompletedAt),
+			csvCell(strings.Join(assignees, "; ")),
+			csvCell(task.Creator.Name),
+			task.CreatedAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// csvCell keeps spreadsheet apps from evaluating user text as a formula
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+	"household-todo-backend/quickadd"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type QuickAddRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// QuickAddTask creates a task from a line of free text such as
+// "take out bins every tuesday 7pm @Sam #chores !high". With ?dryRun=true
+// the text is only parsed and nothing is created.
+func (tc *TaskController) QuickAddTask(c *gin.Context) {
+	householdID := c.Param("id")
+	userID := c.GetString("userID")
+	userHouseholdID := c.GetString("householdID")
+
+	// Enforce that the JWT household matches the path household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var req QuickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	dryRun, _ := strconv.ParseBool(c.Query("dryRun"))
+
+	if !models.IsMember(tc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "User not authorized for this household"})
+		return
+	}
+
+	users, err := models.HouseholdMembers(tc.DB, householdID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household members"})
+		return
+	}
+	members := make([]quickadd.Member, len(users))
+	for i, user := range users {
+		members[i] = quickadd.Member{ID: user.ID, Name: user.Name}
+	}
+
+	settings := models.GetHouseholdSettings(tc.DB, householdID)
+	parse := quickadd.Parse(req.Text, quickadd.Options{
+		Now:       time.Now(),
+		Location:  settings.Location(),
+		WeekStart: settings.WeekStart,
+		Locale:    settings.Locale,
+		Members:   members,
+		UserID:    userID,
+	})
+
+	if strings.TrimSpace(parse.Title) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not find a title in the text", "parse": parse})
+		return
+	}
+	if parse.Category == "" {
+		parse.Category = settings.DefaultCategory
+	}
+	if parse.Priority == "" {
+		parse.Priority = models.PriorityNormal
+	}
+
+	// Validate before a dry run too, so a preview never promises a task that
+	// creating it would reject
+	recurrence, err := normalizeRecurrence(parse.Recurrence, parse.DueDate, settings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "parse": parse})
+		return
+	}
+
+	if dryRun {
+		c.JSON(http.StatusOK, gin.H{"parse": parse})
+		return
+	}
+
+	task := models.Task{
+		Title:       parse.Title,
+		Description: parse.Description,
+		Category:    parse.Category,
+		Priority:    parse.Priority,
+		DueDate:     parse.DueDate,
+		Recurrence:  recurrence,
+		CreatorID:   userID,
+		HouseholdID: householdID,
+	}
+
+	if err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		for _, member := range parse.Assignees {
+			if err := tx.Create(&models.TaskAssignment{TaskID: task.ID, UserID: member.ID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+		return
+	}
+
+	if err := tc.loadTask(&task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"task": task, "parse": parse})
+}
@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errReviewDecided rejects a second decision on the same submission
+var errReviewDecided = errors.New("task is not waiting for review")
+
+type ReviewTaskRequest struct {
+	Note string `json:"note"`
+}
+
+// ApproveTask signs off a task submitted for review. It is completed on
+// behalf of whoever submitted it, who gets its points.
+func (tc *TaskController) ApproveTask(c *gin.Context) {
+	tc.reviewTask(c, models.ReviewApproved)
+}
+
+// RejectTask sends a submitted task back with a note. It stays open.
+func (tc *TaskController) RejectTask(c *gin.Context) {
+	tc.reviewTask(c, models.ReviewRejected)
+}
+
+// reviewTask records the reviewer's decision on a pending task
+func (tc *TaskController) reviewTask(c *gin.Context, status string) {
+	taskID := c.Param("id")
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	var req ReviewTaskRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var task models.Task
+	if err := tc.DB.Where("id = ? AND household_id = ?", taskID, householdID).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	if task.ReviewStatus != models.ReviewPending {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task is not waiting for review"})
+		return
+	}
+	if !canReviewTask(tc.DB, &task, userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the task's reviewer can approve or reject it"})
+		return
+	}
+	if status == models.ReviewApproved && refuseBlocked(c, tc.DB, &task) {
+		return
+	}
+
+	now := time.Now()
+	submitter := *task.SubmittedBy
+	if status == models.ReviewApproved {
+		task.Completed = true
+		task.CompletedAt = &now
+		task.CompletedBy = &submitter
+	}
+	task.ReviewStatus = status
+	task.ReviewedBy = &userID
+	task.ReviewedAt = &now
+	task.ReviewNote = strings.TrimSpace(req.Note)
+
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the first decision on a submission counts
+		result := tx.Model(&models.Task{}).
+			Where("id = ? AND review_status = ?", task.ID, models.ReviewPending).
+			Update("review_status", status)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errReviewDecided
+		}
+
+		if status == models.ReviewApproved {
+			if err := saveCompletion(tx, &task, userID); err != nil {
+				return err
+			}
+		} else if err := tx.Save(&task).Error; err != nil {
+			return err
+		}
+
+		if submitter == userID {
+			return nil
+		}
+		message := fmt.Sprintf("%q was approved", task.Title)
+		if status == models.ReviewRejected {
+			message = fmt.Sprintf("%q needs another look", task.Title)
+		}
+		if task.ReviewNote != "" {
+			message += ": " + task.ReviewNote
+		}
+		return notifyTask(tx, &task, submitter, models.NotificationTaskReviewed, message)
+	})
+	if err == errReviewDecided || err == errCompletionChanged {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task is not waiting for review"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review task"})
+		return
+	}
+
+	// Reload task with relationships
+	if err := tc.loadTask(&task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// toggleReview handles the toggle for a task someone other than its
+// reviewer says is done: it is submitted for review, or a pending
+// submission is withdrawn
+func (tc *TaskController) toggleReview(c *gin.Context, task *models.Task) {
+	userID := c.GetString("userID")
+
+	if task.ReviewStatus != models.ReviewPending && refuseBlocked(c, tc.DB, task) {
+		return
+	}
+
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		if task.ReviewStatus == models.ReviewPending {
+			clearReview(task)
+			return tx.Save(task).Error
+		}
+		return submitForReview(tx, task, userID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+		return
+	}
+
+	// Reload task with relationships
+	if err := tc.loadTask(task); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task"})
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// checkReviewer makes sure a task's reviewer belongs to the household. An
+// empty ID means any admin.
+func (tc *TaskController) checkReviewer(householdID string, reviewerID *string) (*string, error) {
+	if reviewerID == nil || *reviewerID == "" {
+		return nil, nil
+	}
+	if !models.IsMember(tc.DB, *reviewerID, householdID) {
+		return nil, fmt.Errorf("User %s is not a member of this household", *reviewerID)
+	}
+	return reviewerID, nil
+}
+
+// canReviewTask reports whether the user may approve a task: its designated
+// reviewer, or any admin when it has none
+func canReviewTask(db *gorm.DB, task *models.Task, userID string) bool {
+	if task.ReviewerID != nil {
+		return *task.ReviewerID == userID
+	}
+	return models.IsAdmin(db, userID, task.HouseholdID)
+}
+
+// submitForReview marks a task as done by userID pending review, saves it
+// and tells whoever can review it
+func submitForReview(tx *gorm.DB, task *models.Task, userID string) error {
+	now := time.Now()
+	clearReview(task)
+	task.ReviewStatus = models.ReviewPending
+	task.SubmittedBy = &userID
+	task.SubmittedAt = &now
+	if err := tx.Save(task).Error; err != nil {
+		return err
+	}
+
+	var reviewers []string
+	if task.ReviewerID != nil {
+		reviewers = []string{*task.ReviewerID}
+	} else if err := tx.Model(&models.Membership{}).
+		Where("household_id = ? AND role = ?", task.HouseholdID, models.RoleAdmin).
+		Pluck("user_id", &reviewers).Error; err != nil {
+		return err
+	}
+
+	var user models.User
+	if err := tx.Where("id = ?", userID).First(&user).Error; err != nil {
+		return err
+	}
+	for _, reviewer := range reviewers {
+		if reviewer == userID {
+			continue
+		}
+		if err := notifyTask(tx, task, reviewer, models.NotificationReviewRequested,
+			fmt.Sprintf("%s says %q is done and would like it checked", user.Name, task.Title)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearReview forgets a task's submission and any decision on it
+func clearReview(task *models.Task) {
+	task.ReviewStatus = ""
+	task.SubmittedBy = nil
+	task.SubmittedAt = nil
+	task.ReviewedBy = nil
+	task.ReviewedAt = nil
+	task.ReviewNote = ""
+}
+
+// signOff records that a reviewer completed a task that requires
+// verification themselves. A submission waiting for them is completed on
+// the submitter's behalf.
+func signOff(task *models.Task, reviewerID string, at time.Time) {
+	if task.ReviewStatus == models.ReviewPending && task.SubmittedBy != nil {
+		task.CompletedBy = task.SubmittedBy
+	} else {
+		task.SubmittedBy = nil
+		task.SubmittedAt = nil
+	}
+	task.ReviewStatus = models.ReviewApproved
+	task.ReviewedBy = &reviewerID
+	task.ReviewedAt = &at
+	task.ReviewNote = ""
+}
+
+// notifyTask tells a user about something that happened to a task
+func notifyTask(tx *gorm.DB, task *models.Task, userID, kind, message string) error {
+	taskID := task.ID
+	return tx.Create(&models.Notification{
+		UserID:      userID,
+		HouseholdID: task.HouseholdID,
+		Type:        kind,
+		TaskID:      &taskID,
+		Message:     message,
+	}).Error
+}
@@ -0,0 +1,601 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+	"household-todo-backend/starterpacks"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Limits on what a template may contain
+const (
+	maxTemplateItems     = 100
+	maxTemplateDueOffset = 3650
+)
+
+type TemplateController struct {
+	DB *gorm.DB
+}
+
+func NewTemplateController(db *gorm.DB) *TemplateController {
+	return &TemplateController{DB: db}
+}
+
+type CreateTemplateRequest struct {
+	Name        string                    `json:"name" binding:"required"`
+	Description string                    `json:"description"`
+	Items       []models.TaskTemplateItem `json:"items" binding:"required"`
+}
+
+type UpdateTemplateRequest struct {
+	Name        *string                   `json:"name"`
+	Description *string                   `json:"description"`
+	Items       []models.TaskTemplateItem `json:"items"`
+}
+
+type InstantiateTemplateRequest struct {
+	AnchorDate string    `json:"anchorDate"`
+	AssignedTo *[]string `json:"assignedTo"`
+	ItemIDs    []string  `json:"itemIds"`
+}
+
+// StarterPackSummary is a built-in pack with whether the household has
+// already installed it
+type StarterPackSummary struct {
+	starterpacks.Pack
+	Installed bool `json:"installed"`
+}
+
+// GetTemplates lists the household's task templates
+func (tc *TemplateController) GetTemplates(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var templates []models.TaskTemplate
+	if err := tc.DB.Where("household_id = ?", householdID).
+		Preload("Items", orderTemplateItems).
+		Order("name").
+		Find(&templates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate returns one template with its items
+func (tc *TemplateController) GetTemplate(c *gin.Context) {
+	template, ok := tc.findTemplate(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// CreateTemplate saves a single task or a bundle of tasks as a template
+func (tc *TemplateController) CreateTemplate(c *gin.Context) {
+	householdID := c.Param("id")
+	userID := c.GetString("userID")
+	userHouseholdID := c.GetString("householdID")
+
+	// Enforce that the JWT household matches the path household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !models.IsMember(tc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "User not authorized for this household"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	if !tc.canSetItemPoints(c, householdID, req.Items, nil) {
+		return
+	}
+
+	items, err := tc.normalizeTemplateItems(householdID, req.Items)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template := models.TaskTemplate{
+		HouseholdID: householdID,
+		Name:        name,
+		Description: req.Description,
+		CreatorID:   userID,
+		Items:       items,
+	}
+	if err := tc.DB.Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create template"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateTemplate renames a template or replaces its items
+func (tc *TemplateController) UpdateTemplate(c *gin.Context) {
+	var req UpdateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, ok := tc.findTemplate(c)
+	if !ok {
+		return
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
+			return
+		}
+		template.Name = name
+	}
+	if req.Description != nil {
+		template.Description = *req.Description
+	}
+
+	var items []models.TaskTemplateItem
+	if req.Items != nil {
+		if !tc.canSetItemPoints(c, template.HouseholdID, req.Items, template.Items) {
+			return
+		}
+		var err error
+		if items, err = tc.normalizeTemplateItems(template.HouseholdID, req.Items); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Items").Save(template).Error; err != nil {
+			return err
+		}
+		if req.Items == nil {
+			return nil
+		}
+		if err := tx.Where("template_id = ?", template.ID).Delete(&models.TaskTemplateItem{}).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].TemplateID = template.ID
+		}
+		template.Items = items
+		return tx.Create(&template.Items).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate removes a template. Tasks created from it are kept.
+func (tc *TemplateController) DeleteTemplate(c *gin.Context) {
+	template, ok := tc.findTemplate(c)
+	if !ok {
+		return
+	}
+
+	if err := tc.DB.Transaction(func(tx *gorm.DB) error { return models.DeleteTaskTemplate(tx, template) }); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted successfully"})
+}
+
+// InstantiateTemplate creates the template's tasks, with due dates counted
+// from the anchor date in the household's time zone
+func (tc *TemplateController) InstantiateTemplate(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var req InstantiateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, ok := tc.findTemplate(c)
+	if !ok {
+		return
+	}
+
+	settings := models.GetHouseholdSettings(tc.DB, template.HouseholdID)
+	anchor := settings.StartOfDay(time.Now())
+	if req.AnchorDate != "" {
+		date, err := time.ParseInLocation("2006-01-02", req.AnchorDate, settings.Location())
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "anchorDate must be YYYY-MM-DD"})
+			return
+		}
+		anchor = date
+	}
+
+	items := template.Items
+	if req.ItemIDs != nil {
+		byID := make(map[string]models.TaskTemplateItem, len(items))
+		for _, item := range items {
+			byID[item.ID] = item
+		}
+		items = nil
+		for _, id := range req.ItemIDs {
+			item, ok := byID[id]
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown template item " + id})
+				return
+			}
+			items = append(items, item)
+		}
+		if len(items) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "itemIds cannot be empty"})
+			return
+		}
+	}
+
+	members, err := householdMemberIDs(tc.DB, template.HouseholdID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch household members"})
+		return
+	}
+	if req.AssignedTo != nil {
+		for _, id := range *req.AssignedTo {
+			if !members[id] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "User " + id + " is not a member of this household"})
+				return
+			}
+		}
+	}
+
+	tasks := make([]models.Task, len(items))
+	err = tc.DB.Transaction(func(tx *gorm.DB) error {
+		for i, item := range items {
+			task, err := taskFromTemplateItem(item, anchor, settings)
+			if err != nil {
+				return err
+			}
+			task.CreatorID = userID
+			task.HouseholdID = template.HouseholdID
+			if err := tx.Create(&task).Error; err != nil {
+				return err
+			}
+
+			// Default assignees who have since left the household are skipped
+			assignees := item.AssigneeIDs
+			if req.AssignedTo != nil {
+				assignees = *req.AssignedTo
+			}
+			for _, assignee := range assignees {
+				if !members[assignee] {
+					continue
+				}
+				if err := tx.Create(&models.TaskAssignment{TaskID: task.ID, UserID: assignee}).Error; err != nil {
+					return err
+				}
+			}
+
+			if err := models.CreateChecklist(tx, task.ID, item.Checklist); err != nil {
+				return err
+			}
+			tasks[i] = task
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tasks"})
+		return
+	}
+
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	if err := tc.DB.Where("id IN ?", ids).
+		Preload("Creator").
+		Preload("Assignments.User").
+		Preload("Checklist", orderChecklist).
+		Order("due_date IS NULL, due_date, created_at").
+		Find(&tasks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+	decorateTasks(settings, tasks)
+	if err := models.LoadDependencies(tc.DB, tasks); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"anchorDate": anchor.Format("2006-01-02"), "tasks": tasks})
+}
+
+// GetStarterPacks lists the built-in templates a household can install
+func (tc *TemplateController) GetStarterPacks(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	var installed []string
+	if err := tc.DB.Model(&models.TaskTemplate{}).
+		Where("household_id = ? AND starter_pack <> ''", householdID).
+		Pluck("starter_pack", &installed).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch templates"})
+		return
+	}
+
+	packs := starterpacks.All()
+	summaries := make([]StarterPackSummary, len(packs))
+	for i, pack := range packs {
+		summaries[i] = StarterPackSummary{Pack: pack}
+		for _, slug := range installed {
+			if slug == pack.Slug {
+				summaries[i].Installed = true
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, summaries)
+}
+
+// InstallStarterPack copies a built-in pack into the household's templates,
+// where it can be edited like any other template
+func (tc *TemplateController) InstallStarterPack(c *gin.Context) {
+	householdID := c.Param("id")
+	userID := c.GetString("userID")
+	userHouseholdID := c.GetString("householdID")
+
+	// Enforce that the JWT household matches the path household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	if !models.IsMember(tc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "User not authorized for this household"})
+		return
+	}
+
+	pack, ok := starterpacks.Get(c.Param("slug"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Starter pack not found"})
+		return
+	}
+
+	var existing int64
+	tc.DB.Model(&models.TaskTemplate{}).Where("household_id = ? AND starter_pack = ?", householdID, pack.Slug).Count(&existing)
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Starter pack is already installed"})
+		return
+	}
+
+	items, err := tc.normalizeTemplateItems(householdID, pack.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Starter pack is invalid"})
+		return
+	}
+
+	template := models.TaskTemplate{
+		HouseholdID: householdID,
+		Name:        pack.Name,
+		Description: pack.Description,
+		StarterPack: pack.Slug,
+		CreatorID:   userID,
+		Items:       items,
+	}
+	if err := tc.DB.Create(&template).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to install starter pack"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// findTemplate loads the template in the path, which must belong to the JWT
+// household, and responds with 404 if there is none
+func (tc *TemplateController) findTemplate(c *gin.Context) (*models.TaskTemplate, bool) {
+	var template models.TaskTemplate
+	if err := tc.DB.Where("id = ? AND household_id = ?", c.Param("id"), c.GetString("householdID")).
+		Preload("Items", orderTemplateItems).
+		First(&template).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return nil, false
+	}
+	return &template, true
+}
+
+// canSetItemPoints checks that only admins give template items points, as
+// with tasks. Other members may keep the points an item already has; they
+// answer with 403 otherwise.
+func (tc *TemplateController) canSetItemPoints(c *gin.Context, householdID string, items, existing []models.TaskTemplateItem) bool {
+	current := make(map[string]int, len(existing))
+	for _, item := range existing {
+		current[item.ID] = item.Points
+	}
+	for _, item := range items {
+		if points, ok := current[item.ID]; item.Points == 0 || (ok && item.ID != "" && item.Points == points) {
+			continue
+		}
+		if models.IsAdmin(tc.DB, c.GetString("userID"), householdID) {
+			return true
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can set points"})
+		return false
+	}
+	return true
+}
+
+// normalizeTemplateItems validates template items and returns fresh copies
+// ready to be saved in the given order
+func (tc *TemplateController) normalizeTemplateItems(householdID string, items []models.TaskTemplateItem) ([]models.TaskTemplateItem, error) {
+	if len(items) == 0 {
+		return nil, errors.New("a template needs at least one item")
+	}
+	if len(items) > maxTemplateItems {
+		return nil, fmt.Errorf("templates can have at most %d items", maxTemplateItems)
+	}
+
+	settings := models.GetHouseholdSettings(tc.DB, householdID)
+	members, err := householdMemberIDs(tc.DB, householdID)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := make([]models.TaskTemplateItem, len(items))
+	for i, item := range items {
+		item.ID = ""
+		item.TemplateID = ""
+		item.Position = i
+
+		item.Title = strings.TrimSpace(item.Title)
+		if item.Title == "" {
+			return nil, fmt.Errorf("items[%d]: title is required", i)
+		}
+		// An empty category uses the household default when the task is created
+		if item.Category != "" && !item.Category.Valid() {
+			return nil, fmt.Errorf("items[%d]: unknown category %s", i, item.Category)
+		}
+		if item.Priority == "" {
+			item.Priority = models.PriorityNormal
+		}
+		if !item.Priority.Valid() {
+			return nil, fmt.Errorf("items[%d]: priority must be LOW, NORMAL or HIGH", i)
+		}
+
+		if item.Points < 0 || item.Points > models.MaxTaskPoints {
+			return nil, fmt.Errorf("items[%d]: points must be between 0 and %d", i, models.MaxTaskPoints)
+		}
+		if item.Estimate < 0 || item.Estimate > models.MaxEstimateMinutes {
+			return nil, fmt.Errorf("items[%d]: estimateMinutes must be between 0 and %d", i, models.MaxEstimateMinutes)
+		}
+
+		if offset := item.DueOffsetDays; offset != nil && (*offset < -maxTemplateDueOffset || *offset > maxTemplateDueOffset) {
+			return nil, fmt.Errorf("items[%d]: dueOffsetDays must be between -%d and %d", i, maxTemplateDueOffset, maxTemplateDueOffset)
+		}
+		if item.DueTime != "" {
+			if item.DueOffsetDays == nil {
+				return nil, fmt.Errorf("items[%d]: dueTime needs dueOffsetDays", i)
+			}
+			if _, err := time.Parse("15:04", item.DueTime); err != nil {
+				return nil, fmt.Errorf("items[%d]: dueTime must be HH:MM", i)
+			}
+		}
+		if strings.TrimSpace(item.Recurrence) != "" {
+			if item.DueOffsetDays == nil {
+				return nil, fmt.Errorf("items[%d]: recurring items need dueOffsetDays", i)
+			}
+			rule, err := utils.ParseRRule(item.Recurrence, settings.Location())
+			if err != nil {
+				return nil, fmt.Errorf("items[%d]: invalid recurrence: %w", i, err)
+			}
+			item.Recurrence = rule.String()
+		} else {
+			item.Recurrence = ""
+		}
+
+		assignees := []string{}
+		for _, id := range item.AssigneeIDs {
+			if !members[id] {
+				return nil, fmt.Errorf("items[%d]: user %s is not a member of this household", i, id)
+			}
+			assignees = append(assignees, id)
+		}
+		item.AssigneeIDs = assignees
+
+		checklist, err := cleanChecklist(item.Checklist)
+		if err != nil {
+			return nil, fmt.Errorf("items[%d]: %w", i, err)
+		}
+		if checklist == nil {
+			checklist = []string{}
+		}
+		item.Checklist = checklist
+
+		normalized[i] = item
+	}
+	return normalized, nil
+}
+
+// taskFromTemplateItem builds the task a template item describes for the
+// given anchor date
+func taskFromTemplateItem(item models.TaskTemplateItem, anchor time.Time, settings models.HouseholdSettings) (models.Task, error) {
+	task := models.Task{
+		Title:       item.Title,
+		Description: item.Description,
+		Category:    item.Category,
+		Priority:    item.Priority,
+		Points:      item.Points,
+		Estimate:    item.Estimate,
+	}
+	if task.Category == "" {
+		task.Category = settings.DefaultCategory
+	}
+
+	if item.DueOffsetDays != nil {
+		day := anchor.AddDate(0, 0, *item.DueOffsetDays)
+		hour, minute := 0, 0
+		if clock, err := time.Parse("15:04", item.DueTime); err == nil {
+			hour, minute = clock.Hour(), clock.Minute()
+		}
+		due := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, settings.Location())
+		task.DueDate = &due
+	}
+
+	recurrence, err := normalizeRecurrence(item.Recurrence, task.DueDate, settings)
+	if err != nil {
+		return task, err
+	}
+	task.Recurrence = recurrence
+	return task, nil
+}
+
+// householdMemberIDs returns the set of user IDs in a household
+func householdMemberIDs(db *gorm.DB, householdID string) (map[string]bool, error) {
+	var ids []string
+	if err := db.Model(&models.Membership{}).Where("household_id = ?", householdID).Pluck("user_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	members := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		members[id] = true
+	}
+	return members, nil
+}
+
+func orderTemplateItems(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
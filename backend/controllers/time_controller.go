@@ -0,0 +1,431 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultReportWeeks is how many weeks, including the current one, a
+	// time report covers when no range is given
+	defaultReportWeeks = 8
+
+	// maxReportDays is the longest range a time report covers
+	maxReportDays = 366
+
+	maxTimeNoteLength = 500
+)
+
+// errTimerRunning rejects starting a timer on a task the user is already
+// timing
+var errTimerRunning = errors.New("timer already running")
+
+type TimeController struct {
+	DB *gorm.DB
+}
+
+func NewTimeController(db *gorm.DB) *TimeController {
+	return &TimeController{DB: db}
+}
+
+type StartTimerRequest struct {
+	Note string `json:"note"`
+}
+
+type CreateTimeEntryRequest struct {
+	Minutes   int        `json:"minutes" binding:"required,min=1,max=1440"`
+	StartedAt *time.Time `json:"startedAt"` // Defaults to minutes before now
+	Note      string     `json:"note"`
+}
+
+// TimeReportGroup is the time spent by one member, in one category or in
+// one week of a time report
+type TimeReportGroup struct {
+	Key     string `json:"key"`            // User ID, category or first day of the week (YYYY-MM-DD)
+	Name    string `json:"name,omitempty"` // Member name when grouped by member
+	Seconds int    `json:"seconds"`
+	Entries int    `json:"entries"`
+}
+
+// GetTimeEntries lists the time recorded on a task, newest first, with the
+// total and the task's estimate to compare it with
+func (tc *TimeController) GetTimeEntries(c *gin.Context) {
+	task, ok := tc.findTask(c)
+	if !ok {
+		return
+	}
+
+	var entries []models.TimeEntry
+	if err := tc.DB.Where("task_id = ?", task.ID).Preload("User").Order("started_at DESC").Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch time entries"})
+		return
+	}
+
+	total := 0
+	for _, entry := range entries {
+		total += entry.Seconds
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries, "totalSeconds": total, "estimateMinutes": task.Estimate})
+}
+
+// StartTimer starts timing the caller's work on a task. A timer they have
+// running on another task is stopped, since nobody does two chores at once.
+func (tc *TimeController) StartTimer(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	task, ok := tc.findTask(c)
+	if !ok {
+		return
+	}
+	if task.Completed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Task is already done"})
+		return
+	}
+
+	var req StartTimerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	note, ok := cleanTimeNote(c, req.Note)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	entry := models.TimeEntry{
+		TaskID:      task.ID,
+		HouseholdID: task.HouseholdID,
+		UserID:      userID,
+		TaskTitle:   task.Title,
+		Category:    task.Category,
+		StartedAt:   now,
+		Note:        note,
+	}
+	// The new entry is inserted before looking for the user's other running
+	// timers. A second start waits for this one to commit and then finds
+	// its timer, so only one of them keeps running.
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		var running []models.TimeEntry
+		if err := tx.Scopes(models.Running).Where("user_id = ? AND id != ?", userID, entry.ID).Find(&running).Error; err != nil {
+			return err
+		}
+		for i := range running {
+			if running[i].TaskID == task.ID {
+				return errTimerRunning
+			}
+			if _, err := models.StopTimer(tx, &running[i], now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if err == errTimerRunning {
+			c.JSON(http.StatusConflict, gin.H{"error": "Your timer is already running on this task"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start timer"})
+		return
+	}
+
+	tc.respondWithEntry(c, http.StatusCreated, entry.ID)
+}
+
+// StopTimer stops the caller's running timer on a task
+func (tc *TimeController) StopTimer(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	task, ok := tc.findTask(c)
+	if !ok {
+		return
+	}
+
+	var entry models.TimeEntry
+	if err := tc.DB.Scopes(models.Running).Where("task_id = ? AND user_id = ?", task.ID, userID).First(&entry).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No timer is running on this task"})
+		return
+	}
+
+	stopped, err := models.StopTimer(tc.DB, &entry, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to stop timer"})
+		return
+	}
+	if !stopped {
+		c.JSON(http.StatusConflict, gin.H{"error": "Timer was already stopped"})
+		return
+	}
+
+	tc.respondWithEntry(c, http.StatusOK, entry.ID)
+}
+
+// CreateTimeEntry records time the caller spent on a task without a timer
+func (tc *TimeController) CreateTimeEntry(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	task, ok := tc.findTask(c)
+	if !ok {
+		return
+	}
+
+	var req CreateTimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	note, ok := cleanTimeNote(c, req.Note)
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	duration := time.Duration(req.Minutes) * time.Minute
+	startedAt := now.Add(-duration)
+	if req.StartedAt != nil {
+		startedAt = *req.StartedAt
+	}
+	endedAt := startedAt.Add(duration)
+	if endedAt.After(now.Add(time.Minute)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Time entries cannot end in the future"})
+		return
+	}
+
+	entry := models.TimeEntry{
+		TaskID:      task.ID,
+		HouseholdID: task.HouseholdID,
+		UserID:      userID,
+		TaskTitle:   task.Title,
+		Category:    task.Category,
+		StartedAt:   startedAt,
+		EndedAt:     &endedAt,
+		Seconds:     int(duration.Seconds()),
+		Manual:      true,
+		Note:        note,
+	}
+	if err := tc.DB.Create(&entry).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save time entry"})
+		return
+	}
+
+	tc.respondWithEntry(c, http.StatusCreated, entry.ID)
+}
+
+// DeleteTimeEntry removes a time entry, running or not. Only whoever
+// recorded it or an admin can.
+func (tc *TimeController) DeleteTimeEntry(c *gin.Context) {
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	var entry models.TimeEntry
+	if err := tc.DB.Where("id = ? AND household_id = ?", c.Param("id"), householdID).First(&entry).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Time entry not found"})
+		return
+	}
+	if entry.UserID != userID && !models.IsAdmin(tc.DB, userID, householdID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only whoever recorded this time or an admin can delete it"})
+		return
+	}
+
+	if err := tc.DB.Delete(&entry).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete time entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Time entry deleted successfully"})
+}
+
+// GetRunningTimer returns the caller's running timer in their current
+// household, or null
+func (tc *TimeController) GetRunningTimer(c *gin.Context) {
+	var entry models.TimeEntry
+	err := tc.DB.Scopes(models.Running).
+		Where("user_id = ? AND household_id = ?", c.GetString("userID"), c.GetString("householdID")).
+		Order("started_at DESC").
+		First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusOK, nil)
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch timer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetTimeReport adds up the time recorded in a household by member, by
+// category or by week. The range is given as from and to dates (YYYY-MM-DD,
+// both included) in the household's time zone; entries count on the day
+// they started and running timers are left out.
+func (tc *TimeController) GetTimeReport(c *gin.Context) {
+	householdID := c.Param("id")
+	userHouseholdID := c.GetString("householdID")
+
+	// Verify user belongs to the requested household
+	if householdID != userHouseholdID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	by := c.DefaultQuery("by", "member")
+	if by != "member" && by != "category" && by != "week" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "by must be member, category or week"})
+		return
+	}
+
+	settings := models.GetHouseholdSettings(tc.DB, householdID)
+	loc := settings.Location()
+	now := time.Now()
+
+	from := settings.StartOfWeek(now).AddDate(0, 0, -7*(defaultReportWeeks-1))
+	to := settings.StartOfDay(now).AddDate(0, 0, 1)
+	if value := c.Query("from"); value != "" {
+		day, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date (YYYY-MM-DD)"})
+			return
+		}
+		from = day
+	}
+	if value := c.Query("to"); value != "" {
+		day, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date (YYYY-MM-DD)"})
+			return
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+	if !to.After(from) || to.Sub(from) > maxReportDays*24*time.Hour+time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The range must run forwards and cover at most 366 days"})
+		return
+	}
+
+	query := tc.DB.Where("household_id = ? AND ended_at IS NOT NULL AND started_at >= ? AND started_at < ?", householdID, from, to)
+	if userID := c.Query("userId"); userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+	var entries []models.TimeEntry
+	if err := query.Order("started_at").Find(&entries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch time entries"})
+		return
+	}
+
+	var groups []TimeReportGroup
+	index := map[string]int{}
+	add := func(key, name string, entry models.TimeEntry) {
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, TimeReportGroup{Key: key, Name: name})
+		}
+		groups[i].Seconds += entry.Seconds
+		groups[i].Entries++
+	}
+
+	switch by {
+	case "member":
+		// Every member is listed, including those who recorded nothing.
+		// Time recorded by people who have since left is left out.
+		members, err := models.HouseholdMembers(tc.DB, householdID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+			return
+		}
+		names := map[string]string{}
+		for _, member := range members {
+			names[member.ID] = member.Name
+			if userID := c.Query("userId"); userID == "" || userID == member.ID {
+				index[member.ID] = len(groups)
+				groups = append(groups, TimeReportGroup{Key: member.ID, Name: member.Name})
+			}
+		}
+		for _, entry := range entries {
+			if name, ok := names[entry.UserID]; ok {
+				add(entry.UserID, name, entry)
+			}
+		}
+		sort.SliceStable(groups, func(i, j int) bool { return groups[i].Seconds > groups[j].Seconds })
+	case "category":
+		for _, entry := range entries {
+			category := entry.Category
+			if category == "" {
+				category = models.General
+			}
+			add(string(category), "", entry)
+		}
+		sort.SliceStable(groups, func(i, j int) bool { return groups[i].Seconds > groups[j].Seconds })
+	case "week":
+		// Every week in the range is listed, including empty ones
+		for week := settings.StartOfWeek(from); week.Before(to); week = week.AddDate(0, 0, 7) {
+			index[week.Format("2006-01-02")] = len(groups)
+			groups = append(groups, TimeReportGroup{Key: week.Format("2006-01-02")})
+		}
+		for _, entry := range entries {
+			add(settings.StartOfWeek(entry.StartedAt).Format("2006-01-02"), "", entry)
+		}
+	}
+	if groups == nil {
+		groups = []TimeReportGroup{}
+	}
+
+	total := 0
+	for _, entry := range entries {
+		total += entry.Seconds
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"by":           by,
+		"from":         from.Format("2006-01-02"),
+		"to":           to.AddDate(0, 0, -1).Format("2006-01-02"),
+		"totalSeconds": total,
+		"groups":       groups,
+	})
+}
+
+// findTask loads the task named in the path from the caller's household
+func (tc *TimeController) findTask(c *gin.Context) (*models.Task, bool) {
+	var task models.Task
+	if err := tc.DB.Where("id = ? AND household_id = ?", c.Param("id"), c.GetString("householdID")).First(&task).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return nil, false
+	}
+	return &task, true
+}
+
+// respondWithEntry replies with a time entry and the user who recorded it
+func (tc *TimeController) respondWithEntry(c *gin.Context, status int, id string) {
+	var entry models.TimeEntry
+	if err := tc.DB.Preload("User").First(&entry, "id = ?", id).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load time entry"})
+		return
+	}
+	c.JSON(status, entry)
+}
+
+// cleanTimeNote trims a time entry note, answering with an error when it is
+// too long
+func cleanTimeNote(c *gin.Context, note string) (string, bool) {
+	note = strings.TrimSpace(note)
+	if len([]rune(note)) > maxTimeNoteLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Notes can be at most 500 characters"})
+		return "", false
+	}
+	return note, true
+}
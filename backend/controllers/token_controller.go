@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"household-todo-backend/models"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TokenController struct {
+	DB *gorm.DB
+}
+
+func NewTokenController(db *gorm.DB) *TokenController {
+	return &TokenController{DB: db}
+}
+
+type CreateTokenRequest struct {
+	Name      string     `json:"name" binding:"required"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// CreateToken issues a personal access token for the authenticated user. The
+// token itself is only returned in this response.
+func (tc *TokenController) CreateToken(c *gin.Context) {
+	userID := c.GetString("userID")
+	householdID := c.GetString("householdID")
+
+	var req CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if !validScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown scope: " + scope})
+			return
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expiresAt must be in the future"})
+		return
+	}
+
+	secret := models.PersonalAccessTokenPrefix + utils.GenerateSecureToken(32)
+	token := models.PersonalAccessToken{
+		UserID:      userID,
+		HouseholdID: householdID,
+		Name:        req.Name,
+		Scopes:      req.Scopes,
+		TokenPrefix: secret[:len(models.PersonalAccessTokenPrefix)+6],
+		TokenHash:   utils.HashToken(secret),
+		ExpiresAt:   req.ExpiresAt,
+	}
+
+	if err := tc.DB.Create(&token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token":               secret,
+		"personalAccessToken": token,
+	})
+}
+
+// GetTokens lists the authenticated user's personal access tokens
+func (tc *TokenController) GetTokens(c *gin.Context) {
+	userID := c.GetString("userID")
+
+	var tokens []models.PersonalAccessToken
+	if err := tc.DB.Where("user_id = ? AND revoked_at IS NULL", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RevokeToken revokes one of the authenticated user's personal access tokens
+func (tc *TokenController) RevokeToken(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+
+	var token models.PersonalAccessToken
+	if err := tc.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).First(&token).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	if err := tc.DB.Model(&token).Update("revoked_at", time.Now()).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
+
+func validScope(scope string) bool {
+	for _, s := range models.TokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"time"
+	"unicode"
+
+	"household-todo-backend/config"
+	"household-todo-backend/models"
+	"household-todo-backend/storage"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// colorPattern matches the "#rrggbb" colours users can pick
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// UploadAvatar replaces a user's avatar with the image in the multipart
+// "file" field. The largest centred square is cut out and stored in each of
+// models.AvatarSizes.
+func (uc *UserController) UploadAvatar(c *gin.Context) {
+	userID := c.Param("id")
+
+	// Users can only change their own avatar
+	if userID != c.GetString("userID") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, models.MaxAvatarSize+64<<10)
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Avatars can be at most 5 MB"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "An image is required in the \"file\" field"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, models.MaxAvatarSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file"})
+		return
+	}
+	if len(data) > models.MaxAvatarSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Avatars can be at most 5 MB"})
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/gif" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Avatars must be JPEG, PNG or GIF images"})
+		return
+	}
+	img, err := utils.DecodeImage(data)
+	if err != nil {
+		if errors.Is(err, utils.ErrImageTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Image dimensions are too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image could not be read"})
+		return
+	}
+
+	square := utils.CropSquare(img)
+	images := map[string][]byte{}
+	for size, px := range models.AvatarSizes {
+		encoded, err := utils.EncodeJPEG(utils.Resize(square, px, px), 85)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process image"})
+			return
+		}
+		images[size] = encoded
+	}
+
+	// Every upload gets a fresh key, so cached copies of the old avatar are
+	// never served under the new links
+	key := "avatars/" + userID + "/" + utils.GenerateSecureToken(12)
+	for size, encoded := range images {
+		if err := config.Storage.Put(models.AvatarFileKey(key, size), encoded); err != nil {
+			removeAvatarFiles(key)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store avatar"})
+			return
+		}
+	}
+
+	oldKey, err := uc.swapAvatar(userID, key)
+	if err != nil {
+		removeAvatarFiles(key)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save avatar"})
+		return
+	}
+	removeAvatarFiles(oldKey)
+
+	uc.respondWithUser(c, userID)
+}
+
+// DeleteAvatar removes a user's uploaded avatar, so the generated one is
+// shown again
+func (uc *UserController) DeleteAvatar(c *gin.Context) {
+	userID := c.Param("id")
+
+	// Users can only change their own avatar
+	if userID != c.GetString("userID") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
+		return
+	}
+
+	oldKey, err := uc.swapAvatar(userID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove avatar"})
+		return
+	}
+	removeAvatarFiles(oldKey)
+
+	uc.respondWithUser(c, userID)
+}
+
+// GetAvatar serves a user's avatar in one of models.AvatarSizes to anyone
+// with a signed link: the uploaded image if there is one, otherwise an SVG
+// with their emoji or initials on their colour.
+func (uc *UserController) GetAvatar(c *gin.Context) {
+	if !utils.VerifyPathSignature(c.Request.URL.Path, c.Request.URL.Query()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Link is invalid or has expired"})
+		return
+	}
+
+	size := c.Param("size")
+	px, ok := models.AvatarSizes[size]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown avatar size"})
+		return
+	}
+
+	var user models.User
+	if err := uc.DB.Where("id = ?", c.Param("id")).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'; sandbox")
+	c.Header("Cache-Control", "private, max-age=86400")
+
+	if user.AvatarKey != "" {
+		file, err := config.Storage.Open(models.AvatarFileKey(user.AvatarKey, size))
+		if err == nil {
+			defer file.Close()
+			c.Header("Content-Type", "image/jpeg")
+			http.ServeContent(c.Writer, c.Request, "", *user.AvatarUpdatedAt, file)
+			return
+		}
+	}
+
+	text := user.Emoji
+	if text == "" {
+		text = utils.Initials(user.Name)
+	}
+	c.Data(http.StatusOK, "image/svg+xml", utils.AvatarSVG(text, user.Color, px))
+}
+
+// swapAvatar points a user at the avatar stored under key, or at none when
+// key is empty, and returns the key it replaced
+func (uc *UserController) swapAvatar(userID, key string) (string, error) {
+	var oldKey string
+	err := uc.DB.Transaction(func(tx *gorm.DB) error {
+		// Bumping the user first holds the database's write lock until
+		// commit, so a concurrent upload cannot swap the key between reading
+		// it here and overwriting it, and every replaced file gets removed
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("updated_at", time.Now()).Error; err != nil {
+			return err
+		}
+		var user models.User
+		if err := tx.Select("id", "avatar_key").Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+		oldKey = user.AvatarKey
+
+		var uploadedAt *time.Time
+		if key != "" {
+			now := time.Now()
+			uploadedAt = &now
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{"avatar_key": key, "avatar_updated_at": uploadedAt}).Error
+	})
+	return oldKey, err
+}
+
+// respondWithUser replies with a user as UpdateUser does
+func (uc *UserController) respondWithUser(c *gin.Context, userID string) {
+	var user models.User
+	if err := uc.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// removeAvatarFiles deletes every size of the avatar stored under key.
+// Files that are already gone are fine.
+func removeAvatarFiles(key string) error {
+	if key == "" {
+		return nil
+	}
+	for size := range models.AvatarSizes {
+		if err := config.Storage.Delete(models.AvatarFileKey(key, size)); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// validEmoji reports whether s looks like a single emoji: a few code points
+// with at least one symbol and no letters or spacing
+func validEmoji(s string) bool {
+	runes := []rune(s)
+	if len(runes) > 10 || len(s) > 40 {
+		return false
+	}
+	symbol := false
+	for _, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+		if unicode.Is(unicode.So, r) || unicode.Is(unicode.Me, r) {
+			symbol = true
+		}
+	}
+	return symbol
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"net/http"
+	"strings"
 	"time"
 
 	"household-todo-backend/models"
@@ -18,15 +19,18 @@ func NewUserController(db *gorm.DB) *UserController {
 	return &UserController{DB: db}
 }
 
+// UpdateUserRequest changes a user's profile. Fields that are left out stay
+// as they are; an empty emoji removes it.
 type UpdateUserRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+	Emoji *string `json:"emoji"`
 }
 
-// UpdateUser updates a user's information
+// UpdateUser updates a user's name, colour and emoji
 func (uc *UserController) UpdateUser(c *gin.Context) {
 	userID := c.Param("id")
 	authUserID := c.GetString("userID")
-	householdID := c.GetString("householdID")
 
 	// Users can only update their own information
 	if userID != authUserID {
@@ -41,12 +45,33 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 	}
 
 	var user models.User
-	if err := uc.DB.Where("id = ? AND household_id = ?", userID, householdID).First(&user).Error; err != nil {
+	if err := uc.DB.Where("id = ?", userID).First(&user).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	user.Name = req.Name
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Name cannot be empty"})
+			return
+		}
+		user.Name = name
+	}
+	if req.Color != nil {
+		if !colorPattern.MatchString(*req.Color) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Color must be a hex colour such as #3b82f6"})
+			return
+		}
+		user.Color = strings.ToLower(*req.Color)
+	}
+	if req.Emoji != nil {
+		if *req.Emoji != "" && !validEmoji(*req.Emoji) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Emoji must be a single emoji"})
+			return
+		}
+		user.Emoji = *req.Emoji
+	}
 	now := time.Now()
 	user.LastSeen = &now
 
@@ -58,7 +83,9 @@ func (uc *UserController) UpdateUser(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-// LeaveHousehold removes a user from their household
+// LeaveHousehold removes a user from the household they are signed in to.
+// Users who still belong to other households keep their account and are
+// moved to one of those; otherwise the account is deleted.
 func (uc *UserController) LeaveHousehold(c *gin.Context) {
 	userID := c.Param("id")
 	authUserID := c.GetString("userID")
@@ -71,35 +98,66 @@ func (uc *UserController) LeaveHousehold(c *gin.Context) {
 	}
 
 	var user models.User
-	if err := uc.DB.Where("id = ? AND household_id = ?", userID, householdID).First(&user).Error; err != nil {
+	if err := uc.DB.Where("id = ?", userID).First(&user).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	// Check if user is the creator of any tasks
-	var taskCount int64
-	uc.DB.Model(&models.Task{}).Where("creator_id = ?", userID).Count(&taskCount)
-
-	if taskCount > 0 {
-		// Reassign tasks to another household member or mark as unassigned
-		var otherUsers []models.User
-		uc.DB.Where("household_id = ? AND id != ?", user.HouseholdID, userID).Find(&otherUsers)
-
-		if len(otherUsers) > 0 {
-			// Reassign to the first other user
-			newCreatorID := otherUsers[0].ID
-			uc.DB.Model(&models.Task{}).Where("creator_id = ?", userID).Update("creator_id", newCreatorID)
-		} else {
-			// No other users, delete the tasks
-			uc.DB.Where("creator_id = ?", userID).Delete(&models.Task{})
-		}
+	if !models.IsMember(uc.DB, userID, householdID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
 	}
 
-	// Delete user
-	if err := uc.DB.Delete(&user).Error; err != nil {
+	var remainingID string
+	var files []string
+	err := uc.DB.Transaction(func(tx *gorm.DB) error {
+		// Reassign the user's tasks to another household member, or delete
+		// the household if nobody else is left
+		var other models.Membership
+		if err := tx.Where("household_id = ? AND user_id != ?", householdID, userID).Order("created_at").First(&other).Error; err != nil {
+			var err error
+			remainingID, err = detachMember(tx, user, householdID)
+			if err != nil {
+				return err
+			}
+			files, err = purgeHousehold(tx, householdID)
+			return err
+		}
+
+		if err := tx.Model(&models.Task{}).Where("creator_id = ? AND household_id = ?", userID, householdID).
+			Update("creator_id", other.UserID).Error; err != nil {
+			return err
+		}
+
+		// A household always keeps at least one admin
+		var admins int64
+		tx.Model(&models.Membership{}).Where("household_id = ? AND user_id != ? AND role = ?", householdID, userID, models.RoleAdmin).Count(&admins)
+		if admins == 0 {
+			if err := tx.Model(&other).Update("role", models.RoleAdmin).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		remainingID, err = detachMember(tx, user, householdID)
+		return err
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to leave household"})
 		return
 	}
+	removeFiles(files)
+	if remainingID == "" {
+		removeAvatarFiles(user.AvatarKey)
+	}
+
+	if remainingID != "" {
+		c.JSON(http.StatusOK, gin.H{
+			"message":     "Successfully left household",
+			"householdId": remainingID,
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Successfully left household"})
 }
@@ -0,0 +1,141 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+)
+
+// csvColumns maps the header names we recognize to archive fields. Headers
+// are compared in lower case with spaces, dashes and underscores removed.
+var csvColumns = map[string]string{
+	"title":       "title",
+	"name":        "title",
+	"task":        "title",
+	"content":     "title",
+	"description": "description",
+	"notes":       "description",
+	"category":    "category",
+	"priority":    "priority",
+	"duedate":     "dueDate",
+	"due":         "dueDate",
+	"recurrence":  "recurrence",
+	"rrule":       "recurrence",
+	"repeat":      "recurrence",
+	"completed":   "completed",
+	"done":        "completed",
+	"completedat": "completedAt",
+	"assignees":   "assignees",
+	"assignedto":  "assignees",
+	"assignee":    "assignees",
+	"creator":     "creator",
+	"createdby":   "creator",
+}
+
+// parseCSV reads a spreadsheet with a header row. Only a title column is
+// required; assignees are names separated by semicolons and recurrence is an
+// RRULE.
+func parseCSV(data []byte, loc *time.Location) (*models.HouseholdArchive, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("CSV file is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, header := range rows[0] {
+		key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(header)))
+		if field, ok := csvColumns[key]; ok {
+			if _, dup := columns[field]; !dup {
+				columns[field] = i
+			}
+		}
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("CSV needs a title column")
+	}
+
+	archive := newArchive()
+	members := newMemberSet(archive)
+	memberID := func(name string) string {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return ""
+		}
+		id := "name:" + strings.ToLower(name)
+		members.add(id, name)
+		return id
+	}
+
+	for n, row := range rows[1:] {
+		cell := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(row) {
+				return strings.TrimSpace(unguardCSVCell(row[i]))
+			}
+			return ""
+		}
+
+		line := n + 2
+		task := models.ArchivedTask{
+			ID:          fmt.Sprintf("row:%d", line),
+			Title:       cell("title"),
+			Description: cell("description"),
+			Category:    models.TaskCategory(strings.ToUpper(cell("category"))),
+			Priority:    models.TaskPriority(strings.ToUpper(cell("priority"))),
+			Recurrence:  cell("recurrence"),
+			Completed:   parseCompleted(cell("completed")),
+			CreatorID:   memberID(cell("creator")),
+		}
+		if task.Title == "" {
+			continue
+		}
+
+		if task.DueDate, err = parseDate(cell("dueDate"), loc); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if task.CompletedAt, err = parseDate(cell("completedAt"), loc); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if task.CompletedAt != nil {
+			task.Completed = true
+		}
+
+		archive.Tasks = append(archive.Tasks, task)
+		for _, name := range strings.Split(cell("assignees"), ";") {
+			if id := memberID(name); id != "" {
+				archive.Assignments = append(archive.Assignments, models.ArchivedAssignment{TaskID: task.ID, UserID: id})
+			}
+		}
+	}
+
+	return archive, nil
+}
+
+// unguardCSVCell drops the quote our task export puts in front of text a
+// spreadsheet app would otherwise evaluate as a formula
+func unguardCSVCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+func parseCompleted(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1", "x", "done", "completed":
+		return true
+	}
+	return false
+}
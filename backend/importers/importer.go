@@ -0,0 +1,180 @@
+// Package importers converts task exports from this backend and from other
+// todo apps into a models.HouseholdArchive, so a single code path can apply
+// any of them to a household.
+package importers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+)
+
+// Supported import formats
+const (
+	FormatNative  = "native"
+	FormatCSV     = "csv"
+	FormatTodoist = "todoist"
+	FormatTrello  = "trello"
+)
+
+// ErrUnknownFormat is returned when the format cannot be detected
+var ErrUnknownFormat = errors.New("unrecognized import format")
+
+// Parse converts data in the given format into an archive. Dates without a
+// time of day are placed at midnight in loc.
+func Parse(format string, data []byte, loc *time.Location) (*models.HouseholdArchive, error) {
+	switch format {
+	case FormatNative:
+		return parseNative(data)
+	case FormatCSV:
+		return parseCSV(data, loc)
+	case FormatTodoist:
+		return parseTodoist(data, loc)
+	case FormatTrello:
+		return parseTrello(data)
+	}
+	return nil, fmt.Errorf("unsupported import format %q", format)
+}
+
+// Detect guesses the format of an upload from its content type and shape
+func Detect(contentType string, data []byte) (string, error) {
+	if strings.Contains(contentType, "csv") {
+		return FormatCSV, nil
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return "", ErrUnknownFormat
+	}
+
+	switch trimmed[0] {
+	case '[':
+		// The Todoist REST API returns a bare array of tasks
+		return FormatTodoist, nil
+	case '{':
+		var probe map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &probe); err != nil {
+			return "", fmt.Errorf("invalid JSON: %w", err)
+		}
+		if _, ok := probe["household"]; ok {
+			if _, ok := probe["version"]; ok {
+				return FormatNative, nil
+			}
+		}
+		if _, ok := probe["cards"]; ok {
+			return FormatTrello, nil
+		}
+		if _, ok := probe["items"]; ok {
+			return FormatTodoist, nil
+		}
+		return "", ErrUnknownFormat
+	}
+
+	// Anything else that is not JSON is treated as CSV
+	return FormatCSV, nil
+}
+
+// newArchive returns an empty archive ready to be filled by a parser
+func newArchive() *models.HouseholdArchive {
+	return &models.HouseholdArchive{
+		Version:      models.ArchiveVersion,
+		ExportedAt:   time.Now().UTC(),
+		Members:      []models.ArchivedMember{},
+		Tasks:        []models.ArchivedTask{},
+		Assignments:  []models.ArchivedAssignment{},
+		Checklists:   []models.ArchivedChecklist{},
+		Dependencies: []models.ArchivedDependency{},
+		History:      []models.ArchivedEvent{},
+	}
+}
+
+// memberSet collects the people referenced by an import, keyed by a
+// source-specific ID
+type memberSet struct {
+	archive *models.HouseholdArchive
+	seen    map[string]bool
+}
+
+func newMemberSet(archive *models.HouseholdArchive) *memberSet {
+	return &memberSet{archive: archive, seen: make(map[string]bool)}
+}
+
+func (m *memberSet) add(id, name string) {
+	name = strings.TrimSpace(name)
+	if id == "" || name == "" || m.seen[id] {
+		return
+	}
+	m.seen[id] = true
+	m.archive.Members = append(m.archive.Members, models.ArchivedMember{
+		ID:   id,
+		Name: name,
+		Role: models.RoleMember,
+	})
+}
+
+func (m *memberSet) has(id string) bool {
+	return m.seen[id]
+}
+
+// parseDate accepts RFC 3339 timestamps and the date formats other apps
+// commonly export. Values without a zone are read in loc.
+func parseDate(value string, loc *time.Location) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	for _, layout := range []string{
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognized date %q", value)
+}
+
+// flexString decodes JSON strings and numbers alike, since other apps have
+// changed ID types between export versions
+type flexString string
+
+func (s *flexString) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = ""
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var str string
+		if err := json.Unmarshal(data, &str); err != nil {
+			return err
+		}
+		*s = flexString(str)
+		return nil
+	}
+	*s = flexString(strings.TrimSpace(string(data)))
+	return nil
+}
+
+// flexBool decodes JSON booleans as well as 0/1
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.TrimSpace(string(data)) {
+	case "true", "1", `"true"`, `"1"`:
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
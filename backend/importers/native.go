@@ -0,0 +1,20 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"household-todo-backend/models"
+)
+
+// parseNative reads an archive produced by GET /api/households/:id/export
+func parseNative(data []byte) (*models.HouseholdArchive, error) {
+	var archive models.HouseholdArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	if archive.Version < 1 || archive.Version > models.ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+	return &archive, nil
+}
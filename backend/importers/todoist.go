@@ -0,0 +1,118 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"household-todo-backend/models"
+)
+
+// todoistExport covers the Sync API backup format ({ items, collaborators })
+// and, through Items, the bare task array returned by the REST API
+type todoistExport struct {
+	Items         []todoistItem `json:"items"`
+	Collaborators []todoistUser `json:"collaborators"`
+	User          *todoistUser  `json:"user"`
+}
+
+type todoistItem struct {
+	ID             flexString `json:"id"`
+	Content        string     `json:"content"`
+	Description    string     `json:"description"`
+	Checked        flexBool   `json:"checked"`
+	IsCompleted    bool       `json:"is_completed"`
+	IsDeleted      flexBool   `json:"is_deleted"`
+	ResponsibleUID flexString `json:"responsible_uid"`
+	AssigneeID     flexString `json:"assignee_id"`
+	AddedByUID     flexString `json:"added_by_uid"`
+	CreatorID      flexString `json:"creator_id"`
+	CompletedAt    string     `json:"completed_at"`
+	Due            *struct {
+		Date     string `json:"date"`
+		Datetime string `json:"datetime"`
+	} `json:"due"`
+}
+
+type todoistUser struct {
+	ID       flexString `json:"id"`
+	FullName string     `json:"full_name"`
+	Name     string     `json:"name"`
+}
+
+func parseTodoist(data []byte, loc *time.Location) (*models.HouseholdArchive, error) {
+	var export todoistExport
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &export.Items); err != nil {
+			return nil, fmt.Errorf("invalid Todoist export: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("invalid Todoist export: %w", err)
+	}
+
+	archive := newArchive()
+	members := newMemberSet(archive)
+	users := export.Collaborators
+	if export.User != nil {
+		users = append(users, *export.User)
+	}
+	for _, user := range users {
+		name := user.FullName
+		if name == "" {
+			name = user.Name
+		}
+		members.add(string(user.ID), name)
+	}
+
+	for _, item := range export.Items {
+		if item.IsDeleted || item.Content == "" {
+			continue
+		}
+
+		task := models.ArchivedTask{
+			ID:          string(item.ID),
+			Title:       item.Content,
+			Description: item.Description,
+			Completed:   bool(item.Checked) || item.IsCompleted,
+		}
+		if task.ID == "" {
+			task.ID = fmt.Sprintf("item:%d", len(archive.Tasks))
+		}
+
+		creator := string(item.AddedByUID)
+		if creator == "" {
+			creator = string(item.CreatorID)
+		}
+		if members.has(creator) {
+			task.CreatorID = creator
+		}
+
+		var err error
+		if item.Due != nil {
+			due := item.Due.Datetime
+			if due == "" {
+				due = item.Due.Date
+			}
+			if task.DueDate, err = parseDate(due, loc); err != nil {
+				return nil, fmt.Errorf("item %s: %w", task.ID, err)
+			}
+		}
+		if task.CompletedAt, err = parseDate(item.CompletedAt, loc); err != nil {
+			return nil, fmt.Errorf("item %s: %w", task.ID, err)
+		}
+
+		archive.Tasks = append(archive.Tasks, task)
+
+		assignee := string(item.ResponsibleUID)
+		if assignee == "" {
+			assignee = string(item.AssigneeID)
+		}
+		if members.has(assignee) {
+			archive.Assignments = append(archive.Assignments, models.ArchivedAssignment{TaskID: task.ID, UserID: assignee})
+		}
+	}
+
+	return archive, nil
+}
@@ -0,0 +1,79 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"household-todo-backend/models"
+)
+
+// trelloBoard is the subset of Trello's board JSON export we use
+type trelloBoard struct {
+	Cards []struct {
+		ID          string     `json:"id"`
+		Name        string     `json:"name"`
+		Desc        string     `json:"desc"`
+		Due         *time.Time `json:"due"`
+		DueComplete bool       `json:"dueComplete"`
+		Closed      bool       `json:"closed"`
+		IDList      string     `json:"idList"`
+		IDMembers   []string   `json:"idMembers"`
+	} `json:"cards"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Members []struct {
+		ID       string `json:"id"`
+		FullName string `json:"fullName"`
+		Username string `json:"username"`
+	} `json:"members"`
+}
+
+// parseTrello reads a board export. Archived cards and cards on archived
+// lists are skipped.
+func parseTrello(data []byte) (*models.HouseholdArchive, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, fmt.Errorf("invalid Trello export: %w", err)
+	}
+
+	archive := newArchive()
+	members := newMemberSet(archive)
+	for _, member := range board.Members {
+		name := member.FullName
+		if name == "" {
+			name = member.Username
+		}
+		members.add(member.ID, name)
+	}
+
+	closedLists := make(map[string]bool)
+	for _, list := range board.Lists {
+		if list.Closed {
+			closedLists[list.ID] = true
+		}
+	}
+
+	for _, card := range board.Cards {
+		if card.Closed || closedLists[card.IDList] || card.Name == "" {
+			continue
+		}
+
+		archive.Tasks = append(archive.Tasks, models.ArchivedTask{
+			ID:          card.ID,
+			Title:       card.Name,
+			Description: card.Desc,
+			DueDate:     card.Due,
+			Completed:   card.DueComplete,
+		})
+		for _, memberID := range card.IDMembers {
+			if members.has(memberID) {
+				archive.Assignments = append(archive.Assignments, models.ArchivedAssignment{TaskID: card.ID, UserID: memberID})
+			}
+		}
+	}
+
+	return archive, nil
+}
@@ -5,33 +5,89 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"time"
+	_ "time/tzdata" // Household time zones must resolve even without system zoneinfo
 
 	"household-todo-backend/config"
 	"household-todo-backend/controllers"
 	"household-todo-backend/middleware"
 	"household-todo-backend/models"
+	"household-todo-backend/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// Load token signing keys; refuses to start in production without real keys
+	if err := utils.LoadKeys(); err != nil {
+		log.Fatal("Failed to load JWT keys:", err)
+	}
+	if err := utils.LoadSigningKey(); err != nil {
+		log.Fatal("Failed to load URL signing key:", err)
+	}
+
 	// Initialize database
 	db := config.InitDB()
 
+	// Uploaded files such as task attachments
+	config.InitStorage()
+
+	// Household members are linked through the memberships table
+	if err := db.SetupJoinTable(&models.Household{}, "Users", &models.Membership{}); err != nil {
+		log.Fatal("Failed to set up memberships:", err)
+	}
+
 	// Auto migrate the models
 	err := db.AutoMigrate(
 		&models.Household{},
 		&models.User{},
 		&models.Task{},
 		&models.TaskAssignment{},
+		&models.TaskTombstone{},
+		&models.TaskChecklistItem{},
+		&models.TaskTemplate{},
+		&models.TaskTemplateItem{},
+		&models.TaskDependency{},
+		&models.TaskAttachment{},
+		&models.TimeEntry{},
+		&models.Notification{},
+		&models.PointsEntry{},
+		&models.Reward{},
+		&models.RewardRedemption{},
+		&models.Membership{},
+		&models.HouseholdSettings{},
+		&models.Device{},
+		&models.PairingCode{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.PersonalAccessToken{},
+		&models.FeedToken{},
+		&models.RecoveryCode{},
+		&models.AuditEvent{},
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
+	if err := models.BackfillDevices(db); err != nil {
+		log.Fatal("Failed to backfill devices:", err)
+	}
+	if err := models.BackfillMemberships(db); err != nil {
+		log.Fatal("Failed to backfill memberships:", err)
+	}
+	if err := models.FlagLegacyInviteCodes(db); err != nil {
+		log.Fatal("Failed to flag legacy invite codes:", err)
+	}
+
 	// Set up Gin router
 	r := gin.Default()
 
+	// Forwarded client IPs are only believed from configured proxies, so
+	// nobody can dodge rate limits by making up X-Forwarded-For
+	if err := r.SetTrustedProxies(config.TrustedProxies()); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
 	// CORS middleware
 	r.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
@@ -39,7 +95,8 @@ func main() {
 		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
 		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
-		if c.Request.Method == "OPTIONS" {
+		// CalDAV clients use OPTIONS to discover server features
+		if c.Request.Method == "OPTIONS" && !strings.HasPrefix(c.Request.URL.Path, "/caldav") {
 			c.AbortWithStatus(204)
 			return
 		}
@@ -51,6 +108,23 @@ func main() {
 	householdController := controllers.NewHouseholdController(db)
 	taskController := controllers.NewTaskController(db)
 	userController := controllers.NewUserController(db)
+	deviceController := controllers.NewDeviceController(db)
+	authController := controllers.NewAuthController(db)
+	sessionController := controllers.NewSessionController(db)
+	tokenController := controllers.NewTokenController(db)
+	recoveryController := controllers.NewRecoveryController(db)
+	membershipController := controllers.NewMembershipController(db)
+	settingsController := controllers.NewSettingsController(db)
+	importController := controllers.NewImportController(db)
+	feedController := controllers.NewFeedController(db)
+	caldavController := controllers.NewCalDAVController(db)
+	templateController := controllers.NewTemplateController(db)
+	notificationController := controllers.NewNotificationController(db)
+	pointsController := controllers.NewPointsController(db)
+	rewardController := controllers.NewRewardController(db)
+	attachmentController := controllers.NewAttachmentController(db)
+	timeController := controllers.NewTimeController(db)
+	fairnessController := controllers.NewFairnessController(db)
 
 	// API routes
 	api := r.Group("/api")
@@ -59,34 +133,162 @@ func main() {
 		api.POST("/households", householdController.CreateHousehold)
 		api.GET("/households/code/:code", householdController.GetHouseholdByCode)
 		api.POST("/households/code/:code/join", householdController.JoinHousehold)
+		api.POST("/devices/pair", middleware.RateLimit(10, 15*time.Minute), deviceController.PairDevice)
+		api.POST("/recover", middleware.RateLimit(5, 15*time.Minute), recoveryController.RecoverAccount)
+		api.POST("/auth/refresh", authController.Refresh)
+		api.POST("/auth/legacy", middleware.RateLimit(10, 15*time.Minute), authController.ExchangeLegacyToken)
 
 		// Protected routes (authentication required)
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(db))
 		{
 			// Bootstrap endpoint
 			protected.GET("/me", householdController.GetMe)
+			protected.POST("/auth/logout", authController.Logout)
+
+			// Household switcher routes
+			protected.GET("/me/households", membershipController.GetHouseholds)
+			protected.POST("/me/households", householdController.CreateAnotherHousehold)
+			protected.POST("/me/households/join", middleware.RateLimit(10, 15*time.Minute), householdController.JoinAnotherHousehold)
+			protected.POST("/me/households/:id/switch", membershipController.SwitchHousehold)
+
+			// Session routes
+			protected.GET("/me/sessions", sessionController.GetSessions)
+			protected.DELETE("/me/sessions", sessionController.RevokeOtherSessions)
+			protected.DELETE("/me/sessions/:id", sessionController.RevokeSession)
+
+			// Personal access token routes
+			protected.GET("/me/tokens", tokenController.GetTokens)
+			protected.POST("/me/tokens", tokenController.CreateToken)
+			protected.DELETE("/me/tokens/:id", tokenController.RevokeToken)
+
+			// Calendar feeds
+			protected.GET("/me/feeds", feedController.GetFeeds)
+			protected.POST("/me/feeds", feedController.CreateFeed)
+			protected.DELETE("/me/feeds/:id", feedController.RevokeFeed)
+
+			// Notification routes
+			protected.GET("/me/notifications", notificationController.GetNotifications)
+			protected.POST("/me/notifications/read", notificationController.MarkNotificationsRead)
+
+			// Recovery code routes
+			protected.GET("/me/recovery-codes", recoveryController.GetRecoveryCodeStatus)
+			protected.POST("/me/recovery-codes", recoveryController.GenerateRecoveryCodes)
+
+			// Device routes
+			protected.GET("/me/devices", deviceController.GetDevices)
+			protected.DELETE("/me/devices/:id", deviceController.RemoveDevice)
+			protected.POST("/me/devices/pairing", deviceController.CreatePairingCode)
+			protected.GET("/me/devices/pairing/qr", deviceController.GetPairingQRCode)
 
 			// Household routes
+			protected.PUT("/households/:id", householdController.UpdateHousehold)
+			protected.DELETE("/households/:id", householdController.DeleteHousehold)
+			protected.GET("/households/:id/export", householdController.ExportHousehold)
+			protected.POST("/households/import", importController.ImportHousehold)
 			protected.GET("/households/:id/users", householdController.GetHouseholdUsers)
+			protected.PUT("/households/:id/users/:userId/availability", membershipController.UpdateAvailability)
 			protected.GET("/households/:id/invite", householdController.GetInviteCode)
 			protected.POST("/households/:id/invite/refresh", householdController.RefreshInviteCode)
+			protected.GET("/households/:id/invite/qr", householdController.GetInviteQRCode)
+			protected.GET("/households/:id/settings", settingsController.GetSettings)
+			protected.PUT("/households/:id/settings", settingsController.UpdateSettings)
 
 			// Task routes
 			protected.GET("/households/:id/tasks", taskController.GetHouseholdTasks)
 			protected.POST("/households/:id/tasks", taskController.CreateTask)
+			protected.POST("/households/:id/tasks/quick", taskController.QuickAddTask)
+			protected.GET("/households/:id/tasks/export", taskController.ExportTasks)
 			protected.PUT("/tasks/:id", taskController.UpdateTask)
 			protected.DELETE("/tasks/:id", taskController.DeleteTask)
 			protected.PATCH("/tasks/:id/toggle", taskController.ToggleTaskCompletion)
+			protected.POST("/tasks/:id/approve", taskController.ApproveTask)
+			protected.POST("/tasks/:id/reject", taskController.RejectTask)
 			protected.POST("/tasks/:id/assign", taskController.AssignTask)
 			protected.DELETE("/tasks/:id/assign/:userId", taskController.UnassignTask)
+			protected.POST("/tasks/:id/auto-assign", taskController.AutoAssignTask)
+			protected.PATCH("/tasks/:id/checklist/:itemId", taskController.UpdateChecklistItem)
+			protected.POST("/tasks/:id/dependencies", taskController.AddDependency)
+			protected.DELETE("/tasks/:id/dependencies/:blockerId", taskController.RemoveDependency)
+
+			// Attachment routes
+			protected.GET("/tasks/:id/attachments", attachmentController.GetAttachments)
+			protected.POST("/tasks/:id/attachments", attachmentController.UploadAttachment)
+			protected.DELETE("/tasks/:id/attachments/:attachmentId", attachmentController.DeleteAttachment)
+			protected.GET("/households/:id/storage", attachmentController.GetStorageUsage)
+
+			// Time tracking routes
+			protected.GET("/tasks/:id/time-entries", timeController.GetTimeEntries)
+			protected.POST("/tasks/:id/time-entries", timeController.CreateTimeEntry)
+			protected.POST("/tasks/:id/timer/start", timeController.StartTimer)
+			protected.POST("/tasks/:id/timer/stop", timeController.StopTimer)
+			protected.DELETE("/time-entries/:id", timeController.DeleteTimeEntry)
+			protected.GET("/me/timer", timeController.GetRunningTimer)
+			protected.GET("/households/:id/time-report", timeController.GetTimeReport)
+
+			// Points routes
+			protected.GET("/households/:id/leaderboard", pointsController.GetLeaderboard)
+			protected.GET("/households/:id/fairness", fairnessController.GetFairness)
+			protected.GET("/households/:id/points", pointsController.GetLedger)
+			protected.GET("/households/:id/points/balance", pointsController.GetBalance)
+
+			// Reward routes
+			protected.GET("/households/:id/rewards", rewardController.GetRewards)
+			protected.POST("/households/:id/rewards", rewardController.CreateReward)
+			protected.GET("/households/:id/redemptions", rewardController.GetRedemptions)
+			protected.PUT("/rewards/:id", rewardController.UpdateReward)
+			protected.DELETE("/rewards/:id", rewardController.DeleteReward)
+			protected.POST("/rewards/:id/redeem", rewardController.RedeemReward)
+			protected.POST("/redemptions/:id/approve", rewardController.ApproveRedemption)
+			protected.POST("/redemptions/:id/reject", rewardController.RejectRedemption)
+			protected.POST("/redemptions/:id/cancel", rewardController.CancelRedemption)
+
+			// Task template routes
+			protected.GET("/households/:id/templates", templateController.GetTemplates)
+			protected.POST("/households/:id/templates", templateController.CreateTemplate)
+			protected.GET("/households/:id/templates/packs", templateController.GetStarterPacks)
+			protected.POST("/households/:id/templates/packs/:slug", templateController.InstallStarterPack)
+			protected.GET("/templates/:id", templateController.GetTemplate)
+			protected.PUT("/templates/:id", templateController.UpdateTemplate)
+			protected.DELETE("/templates/:id", templateController.DeleteTemplate)
+			protected.POST("/templates/:id/instantiate", templateController.InstantiateTemplate)
 
 			// User routes
 			protected.PUT("/users/:id", userController.UpdateUser)
 			protected.DELETE("/users/:id", userController.LeaveHousehold)
+			protected.PUT("/users/:id/avatar", userController.UploadAvatar)
+			protected.DELETE("/users/:id/avatar", userController.DeleteAvatar)
 		}
 	}
 
+	// Public keys for services that verify our access tokens
+	r.GET("/.well-known/jwks.json", authController.JWKS)
+
+	// Calendar feeds authenticate with the secret in the URL
+	r.GET("/feeds/:token", feedController.GetFeed)
+
+	// Attachment downloads and avatars authenticate with a signature in the URL
+	r.GET("/files/attachments/:id", attachmentController.DownloadAttachment)
+	r.GET("/files/attachments/:id/thumbnail", attachmentController.DownloadAttachment)
+	r.GET("/files/avatars/:id/:size", userController.GetAvatar)
+
+	// CalDAV access to household tasks for Apple Reminders, Thunderbird and
+	// DAVx5, authenticated with personal access tokens as passwords
+	r.Any("/.well-known/caldav", caldavController.WellKnown)
+	r.Handle("PROPFIND", "/.well-known/caldav", caldavController.WellKnown)
+	r.OPTIONS("/caldav/*path", caldavController.Options)
+	caldav := r.Group("/caldav")
+	caldav.Use(middleware.CalDAVAuthMiddleware(db))
+	{
+		caldav.Handle("PROPFIND", "/*path", caldavController.Propfind)
+		caldav.Handle("PROPPATCH", "/*path", caldavController.Proppatch)
+		caldav.Handle("REPORT", "/*path", caldavController.Report)
+		caldav.GET("/*path", caldavController.Get)
+		caldav.HEAD("/*path", caldavController.Get)
+		caldav.PUT("/*path", caldavController.Put)
+		caldav.DELETE("/*path", caldavController.Delete)
+	}
+
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
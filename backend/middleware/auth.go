@@ -3,14 +3,23 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
+	"household-todo-backend/models"
 	"household-todo-backend/utils"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-// AuthMiddleware validates JWT tokens and adds user info to context
-func AuthMiddleware() gin.HandlerFunc {
+// sessionActivityInterval limits how often a session's last-used time is written
+const sessionActivityInterval = time.Minute
+
+// AuthMiddleware validates JWT tokens and adds user info to context. Tokens
+// whose session has been revoked (logout, removed device) are rejected.
+// Personal access tokens are accepted too, limited to the routes their scopes
+// cover.
+func AuthMiddleware(db *gorm.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -29,6 +38,11 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Extract the token
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
+		if strings.HasPrefix(tokenString, models.PersonalAccessTokenPrefix) {
+			authenticatePersonalAccessToken(c, db, tokenString)
+			return
+		}
+
 		// Validate the token
 		claims, err := utils.ValidateJWT(tokenString)
 		if err != nil {
@@ -37,11 +51,134 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Tokens issued before sessions existed carry no jti; clients trade
+		// them for a session at POST /api/auth/legacy
+		if claims.ID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token predates sessions", "code": "legacy_token"})
+			c.Abort()
+			return
+		}
+
+		// Make sure the session has not been revoked
+		var session models.Session
+		if db.Where("id = ? AND user_id = ?", claims.ID, claims.UserID).First(&session).Error != nil || session.RevokedAt != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			c.Abort()
+			return
+		}
+
+		// Access tokens issued before the session switched household are stale
+		if session.HouseholdID != claims.HouseholdID {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token was issued for another household"})
+			c.Abort()
+			return
+		}
+
+		// Track activity, but only write once a minute per session
+		now := time.Now()
+		if session.LastUsedAt == nil || now.Sub(*session.LastUsedAt) > sessionActivityInterval || session.IPAddress != c.ClientIP() {
+			db.Model(&session).Updates(map[string]interface{}{"last_used_at": now, "ip_address": c.ClientIP()})
+		}
+
 		// Add user info to context
 		c.Set("userID", claims.UserID)
 		c.Set("householdID", claims.HouseholdID)
 		c.Set("deviceID", claims.DeviceID)
+		c.Set("sessionID", claims.ID)
 
 		c.Next()
 	}
 }
+
+// authenticatePersonalAccessToken validates a personal access token and checks
+// it carries the scope the matched route needs
+func authenticatePersonalAccessToken(c *gin.Context, db *gorm.DB, tokenString string) {
+	var token models.PersonalAccessToken
+	if err := db.Where("token_hash = ?", utils.HashToken(tokenString)).First(&token).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Abort()
+		return
+	}
+
+	now := time.Now()
+	if !tokenActive(token, now) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has expired or been revoked"})
+		c.Abort()
+		return
+	}
+
+	scope, allowed := requiredScope(c.Request.Method, c.FullPath())
+	if !allowed || !token.HasScope(scope) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Token is not allowed to access this endpoint"})
+		c.Abort()
+		return
+	}
+
+	trackTokenUsage(db, token, now)
+
+	// Add user info to context
+	c.Set("userID", token.UserID)
+	c.Set("householdID", token.HouseholdID)
+	c.Set("tokenID", token.ID)
+
+	c.Next()
+}
+
+// CalDAVAuthMiddleware authenticates calendar clients, which use HTTP Basic
+// auth with a personal access token as an app-specific password (the user
+// name is ignored). Bearer personal access tokens work too. Reads need the
+// tasks:read scope and changes tasks:write.
+func CalDAVAuthMiddleware(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret := ""
+		if _, password, ok := c.Request.BasicAuth(); ok {
+			secret = password
+		} else if header := c.GetHeader("Authorization"); strings.HasPrefix(header, "Bearer ") {
+			secret = strings.TrimPrefix(header, "Bearer ")
+		}
+
+		var token models.PersonalAccessToken
+		now := time.Now()
+		if !strings.HasPrefix(secret, models.PersonalAccessTokenPrefix) ||
+			db.Where("token_hash = ?", utils.HashToken(secret)).First(&token).Error != nil ||
+			!tokenActive(token, now) ||
+			!models.IsMember(db, token.UserID, token.HouseholdID) {
+			c.Header("WWW-Authenticate", `Basic realm="Household Todo", charset="UTF-8"`)
+			c.String(http.StatusUnauthorized, "Unauthorized")
+			c.Abort()
+			return
+		}
+
+		scope := models.ScopeTasksRead
+		switch c.Request.Method {
+		case http.MethodPut, http.MethodDelete, "PROPPATCH", "MKCOL", "MKCALENDAR", "MOVE", "COPY":
+			scope = models.ScopeTasksWrite
+		}
+		if !token.HasScope(scope) {
+			c.String(http.StatusForbidden, "Token is missing the "+scope+" scope")
+			c.Abort()
+			return
+		}
+
+		trackTokenUsage(db, token, now)
+
+		c.Set("userID", token.UserID)
+		c.Set("householdID", token.HouseholdID)
+		c.Set("tokenID", token.ID)
+		c.Set("tokenScopes", token.Scopes)
+
+		c.Next()
+	}
+}
+
+func tokenActive(token models.PersonalAccessToken, now time.Time) bool {
+	return token.RevokedAt == nil && (token.ExpiresAt == nil || now.Before(*token.ExpiresAt))
+}
+
+// trackTokenUsage records when a token was used, but only writes once a
+// minute per token
+func trackTokenUsage(db *gorm.DB, token models.PersonalAccessToken, now time.Time) {
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > sessionActivityInterval {
+		db.Model(&token).Update("last_used_at", now)
+	}
+}
@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"household-todo-backend/models"
+	"household-todo-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const testSecret = "middleware-test-secret"
+
+// newTestDB opens a fresh SQLite database for one test
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	t.Setenv("JWT_SECRET", testSecret)
+	t.Setenv("JWT_KEYS", "")
+	t.Setenv("JWT_KEYS_FILE", "")
+	if err := utils.LoadKeys(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Session{}, &models.PersonalAccessToken{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newTestRouter serves a few protected routes that echo the authenticated
+// user and household
+func newTestRouter(db *gorm.DB) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	protected := r.Group("/api", AuthMiddleware(db))
+	echo := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"userID": c.GetString("userID"), "householdID": c.GetString("householdID")})
+	}
+	protected.GET("/me", echo)
+	protected.GET("/households/:id/tasks", echo)
+	protected.POST("/households/:id/tasks", echo)
+	protected.POST("/auth/logout", echo)
+	return r
+}
+
+func request(r *gin.Engine, method, path, token string) (int, map[string]string) {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var body map[string]string
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w.Code, body
+}
+
+func signClaims(t *testing.T, claims utils.JWTClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthMiddlewareSessions(t *testing.T) {
+	db := newTestDB(t)
+	r := newTestRouter(db)
+
+	active := models.Session{UserID: "user-1", HouseholdID: "house-1", DeviceID: "device-1"}
+	moved := models.Session{UserID: "user-1", HouseholdID: "house-2", DeviceID: "device-2"}
+	revokedAt := time.Now()
+	revoked := models.Session{UserID: "user-1", HouseholdID: "house-1", DeviceID: "device-3", RevokedAt: &revokedAt}
+	for _, session := range []*models.Session{&active, &moved, &revoked} {
+		if err := db.Create(session).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	token := func(userID, householdID, sessionID string) string {
+		t.Helper()
+		token, err := utils.GenerateJWT(userID, householdID, "device", sessionID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		status int
+		code   string
+	}{
+		{"active session", token("user-1", "house-1", active.ID), http.StatusOK, ""},
+		{"no token", "", http.StatusUnauthorized, ""},
+		{"malformed token", "not-a-jwt", http.StatusUnauthorized, ""},
+		{"revoked session", token("user-1", "house-1", revoked.ID), http.StatusUnauthorized, ""},
+		{"unknown session", token("user-1", "house-1", "missing"), http.StatusUnauthorized, ""},
+		{"session of another user", token("user-2", "house-1", active.ID), http.StatusUnauthorized, ""},
+		{"session moved to another household", token("user-1", "house-1", moved.ID), http.StatusUnauthorized, ""},
+		{"token for another household", token("user-1", "house-2", active.ID), http.StatusUnauthorized, ""},
+		{"token without session", signClaims(t, utils.JWTClaims{
+			UserID: "user-1", HouseholdID: "house-1", DeviceID: "device-1",
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		}), http.StatusUnauthorized, "legacy_token"},
+		{"expired token", signClaims(t, utils.JWTClaims{
+			UserID: "user-1", HouseholdID: "house-1", DeviceID: "device-1",
+			RegisteredClaims: jwt.RegisteredClaims{ID: active.ID, ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))},
+		}), http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request(r, http.MethodGet, "/api/me", tt.token)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.status, body)
+			}
+			if body["code"] != tt.code {
+				t.Errorf("code = %q, want %q", body["code"], tt.code)
+			}
+			if status == http.StatusOK && (body["userID"] != "user-1" || body["householdID"] != "house-1") {
+				t.Errorf("context = %v, want user-1 in house-1", body)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareRevocationIsImmediate(t *testing.T) {
+	db := newTestDB(t)
+	r := newTestRouter(db)
+
+	session := models.Session{UserID: "user-1", HouseholdID: "house-1", DeviceID: "device-1"}
+	if err := db.Create(&session).Error; err != nil {
+		t.Fatal(err)
+	}
+	token, err := utils.GenerateJWT("user-1", "house-1", "device-1", session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status, _ := request(r, http.MethodGet, "/api/me", token); status != http.StatusOK {
+		t.Fatalf("before revocation: status = %d, want 200", status)
+	}
+	if err := db.Model(&session).Update("revoked_at", time.Now()).Error; err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := request(r, http.MethodGet, "/api/me", token); status != http.StatusUnauthorized {
+		t.Fatalf("after revocation: status = %d, want 401", status)
+	}
+}
+
+func TestAuthMiddlewarePersonalAccessTokens(t *testing.T) {
+	db := newTestDB(t)
+	r := newTestRouter(db)
+
+	create := func(scopes []string, expiresAt, revokedAt *time.Time) string {
+		t.Helper()
+		secret := models.PersonalAccessTokenPrefix + utils.GenerateSecureToken(32)
+		token := models.PersonalAccessToken{
+			UserID:      "user-1",
+			HouseholdID: "house-1",
+			Name:        "script",
+			Scopes:      scopes,
+			TokenHash:   utils.HashToken(secret),
+			ExpiresAt:   expiresAt,
+			RevokedAt:   revokedAt,
+		}
+		if err := db.Create(&token).Error; err != nil {
+			t.Fatal(err)
+		}
+		return secret
+	}
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	reader := create([]string{models.ScopeTasksRead}, &future, nil)
+	writer := create([]string{models.ScopeTasksRead, models.ScopeTasksWrite}, nil, nil)
+	expired := create([]string{models.ScopeTasksRead}, &past, nil)
+	revoked := create([]string{models.ScopeTasksRead}, nil, &past)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{"read with tasks:read", http.MethodGet, "/api/households/house-1/tasks", reader, http.StatusOK},
+		{"write without tasks:write", http.MethodPost, "/api/households/house-1/tasks", reader, http.StatusForbidden},
+		{"write with tasks:write", http.MethodPost, "/api/households/house-1/tasks", writer, http.StatusOK},
+		{"missing household:read", http.MethodGet, "/api/me", writer, http.StatusForbidden},
+		{"route closed to tokens", http.MethodPost, "/api/auth/logout", writer, http.StatusForbidden},
+		{"expired token", http.MethodGet, "/api/households/house-1/tasks", expired, http.StatusUnauthorized},
+		{"revoked token", http.MethodGet, "/api/households/house-1/tasks", revoked, http.StatusUnauthorized},
+		{"unknown token", http.MethodGet, "/api/households/house-1/tasks", models.PersonalAccessTokenPrefix + "guess", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := request(r, tt.method, tt.path, tt.token)
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d (%v)", status, tt.status, body)
+			}
+			if status == http.StatusOK && body["householdID"] != "house-1" {
+				t.Errorf("householdID = %q, want house-1", body["householdID"])
+			}
+		})
+	}
+}
+
+func TestTokenRouteScopesAreKnown(t *testing.T) {
+	known := make(map[string]bool)
+	for _, scope := range models.TokenScopes {
+		known[scope] = true
+	}
+	for route, scope := range tokenRouteScopes {
+		if !known[scope] {
+			t.Errorf("%s needs unknown scope %q", route, scope)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit allows each client IP at most limit requests per window on the
+// routes it guards. Counters live in memory, which is enough for a single
+// household server.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	clients := make(map[string]*rateWindow)
+	lastSweep := time.Now()
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		// Drop expired windows now and then so the map cannot grow forever
+		if now.Sub(lastSweep) > window {
+			for key, w := range clients {
+				if now.Sub(w.start) > window {
+					delete(clients, key)
+				}
+			}
+			lastSweep = now
+		}
+
+		w, ok := clients[ip]
+		if !ok || now.Sub(w.start) > window {
+			w = &rateWindow{start: now}
+			clients[ip] = w
+		}
+		w.count++
+		count, retryAfter := w.count, w.start.Add(window).Sub(now)
+		mu.Unlock()
+
+		if count > limit {
+			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many attempts, try again later"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
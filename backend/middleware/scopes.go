@@ -0,0 +1,51 @@
+package middleware
+
+import "household-todo-backend/models"
+
+// tokenRouteScopes lists the routes personal access tokens may call, keyed by
+// method and route pattern, with the scope each one needs. Routes that are not
+// listed are only available to device sessions.
+var tokenRouteScopes = map[string]string{
+	"GET /api/me":                                     models.ScopeHouseholdRead,
+	"GET /api/households/:id/leaderboard":             models.ScopeHouseholdRead,
+	"GET /api/households/:id/fairness":                models.ScopeHouseholdRead,
+	"GET /api/households/:id/points":                  models.ScopeHouseholdRead,
+	"GET /api/households/:id/points/balance":          models.ScopeHouseholdRead,
+	"GET /api/households/:id/rewards":                 models.ScopeHouseholdRead,
+	"GET /api/households/:id/redemptions":             models.ScopeHouseholdRead,
+	"GET /api/households/:id/users":                   models.ScopeHouseholdRead,
+	"GET /api/households/:id/tasks":                   models.ScopeTasksRead,
+	"POST /api/households/:id/tasks":                  models.ScopeTasksWrite,
+	"POST /api/households/:id/tasks/quick":            models.ScopeTasksWrite,
+	"GET /api/households/:id/tasks/export":            models.ScopeTasksRead,
+	"PUT /api/tasks/:id":                              models.ScopeTasksWrite,
+	"DELETE /api/tasks/:id":                           models.ScopeTasksWrite,
+	"POST /api/tasks/:id/approve":                     models.ScopeTasksWrite,
+	"POST /api/tasks/:id/reject":                      models.ScopeTasksWrite,
+	"PATCH /api/tasks/:id/toggle":                     models.ScopeTasksWrite,
+	"POST /api/tasks/:id/assign":                      models.ScopeTasksWrite,
+	"DELETE /api/tasks/:id/assign/:userId":            models.ScopeTasksWrite,
+	"POST /api/tasks/:id/auto-assign":                 models.ScopeTasksWrite,
+	"PATCH /api/tasks/:id/checklist/:itemId":          models.ScopeTasksWrite,
+	"POST /api/tasks/:id/dependencies":                models.ScopeTasksWrite,
+	"DELETE /api/tasks/:id/dependencies/:blockerId":   models.ScopeTasksWrite,
+	"GET /api/tasks/:id/attachments":                  models.ScopeTasksRead,
+	"POST /api/tasks/:id/attachments":                 models.ScopeTasksWrite,
+	"DELETE /api/tasks/:id/attachments/:attachmentId": models.ScopeTasksWrite,
+	"GET /api/households/:id/storage":                 models.ScopeHouseholdRead,
+	"GET /api/tasks/:id/time-entries":                 models.ScopeTasksRead,
+	"POST /api/tasks/:id/time-entries":                models.ScopeTasksWrite,
+	"POST /api/tasks/:id/timer/start":                 models.ScopeTasksWrite,
+	"POST /api/tasks/:id/timer/stop":                  models.ScopeTasksWrite,
+	"GET /api/households/:id/time-report":             models.ScopeHouseholdRead,
+	"GET /api/households/:id/templates":               models.ScopeTasksRead,
+	"GET /api/templates/:id":                          models.ScopeTasksRead,
+	"POST /api/templates/:id/instantiate":             models.ScopeTasksWrite,
+}
+
+// requiredScope returns the scope a personal access token needs for the
+// matched route, and false if such tokens may not call it at all
+func requiredScope(method, fullPath string) (string, bool) {
+	scope, ok := tokenRouteScopes[method+" "+fullPath]
+	return scope, ok
+}
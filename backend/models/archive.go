@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// ArchiveVersion is the version of the household archive format. Importers
+// should reject archives with a newer version than they understand. Version 2
+// added checklists and dependencies.
+const ArchiveVersion = 2
+
+// HouseholdArchive is a complete, self-contained export of a household. IDs
+// are kept so references between members, tasks and assignments resolve, but
+// an importer is expected to assign new ones.
+type HouseholdArchive struct {
+	Version      int                  `json:"version"`
+	ExportedAt   time.Time            `json:"exportedAt"`
+	Household    ArchivedHousehold    `json:"household"`
+	Settings     HouseholdSettings    `json:"settings"`
+	Members      []ArchivedMember     `json:"members"`
+	Tasks        []ArchivedTask       `json:"tasks"`
+	Assignments  []ArchivedAssignment `json:"assignments"`
+	Checklists   []ArchivedChecklist  `json:"checklists"`
+	Dependencies []ArchivedDependency `json:"dependencies"`
+	History      []ArchivedEvent      `json:"history"`
+}
+
+type ArchivedHousehold struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ArchivedMember leaves out device IDs, which act as sign-in credentials
+type ArchivedMember struct {
+	ID       string     `json:"id"`
+	Name     string     `json:"name"`
+	Color    string     `json:"color,omitempty"`
+	Emoji    string     `json:"emoji,omitempty"`
+	Role     string     `json:"role"`
+	JoinedAt time.Time  `json:"joinedAt"`
+	LastSeen *time.Time `json:"lastSeen"`
+}
+
+type ArchivedTask struct {
+	ID          string       `json:"id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	Category    TaskCategory `json:"category"`
+	Priority    TaskPriority `json:"priority,omitempty"`
+	Points      int          `json:"points,omitempty"`
+	Estimate    int          `json:"estimateMinutes,omitempty"`
+	DueDate     *time.Time   `json:"dueDate"`
+	Recurrence  string       `json:"recurrence,omitempty"`
+	Completed   bool         `json:"completed"`
+	CreatorID   string       `json:"creatorId"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+	CompletedAt *time.Time   `json:"completedAt"`
+	CompletedBy *string      `json:"completedBy"`
+
+	RequiresVerification bool    `json:"requiresVerification,omitempty"`
+	ReviewerID           *string `json:"reviewerId,omitempty"`
+}
+
+type ArchivedAssignment struct {
+	TaskID    string    `json:"taskId"`
+	UserID    string    `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ArchivedChecklist is one checklist item of a task
+type ArchivedChecklist struct {
+	TaskID   string `json:"taskId"`
+	Position int    `json:"position"`
+	Title    string `json:"title"`
+	Done     bool   `json:"done"`
+}
+
+// ArchivedDependency says the task waits on the blocker
+type ArchivedDependency struct {
+	TaskID    string    `json:"taskId"`
+	BlockerID string    `json:"blockerId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ArchivedEvent is an audit event without the IP address it came from
+type ArchivedEvent struct {
+	UserID    string    `json:"userId"`
+	Action    string    `json:"action"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"createdAt"`
+}
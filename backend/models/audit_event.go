@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Audit event actions
+const (
+	AuditRecoveryCodesGenerated = "recovery_codes.generated"
+	AuditAccountRecovered       = "account.recovered"
+	AuditHouseholdRenamed       = "household.renamed"
+)
+
+// AuditEvent records a security-relevant action taken in a household
+type AuditEvent struct {
+	ID          string    `json:"id" gorm:"primarykey"`
+	HouseholdID string    `json:"householdId" gorm:"not null;index"`
+	UserID      string    `json:"userId"`
+	Action      string    `json:"action" gorm:"not null"`
+	Detail      string    `json:"detail"`
+	IPAddress   string    `json:"ipAddress"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (a *AuditEvent) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return
+}
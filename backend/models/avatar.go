@@ -0,0 +1,85 @@
+package models
+
+import (
+	"hash/fnv"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"household-todo-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// MaxAvatarSize is the largest image accepted as an avatar, in bytes
+const MaxAvatarSize = 5 << 20
+
+// AvatarSizes are the square sizes, in pixels, avatars are stored at
+var AvatarSizes = map[string]int{
+	"small":  64,
+	"medium": 128,
+	"large":  256,
+}
+
+// avatarColors are the colours handed out to users who have not picked one
+var avatarColors = []string{
+	"#ef4444", "#f97316", "#f59e0b", "#84cc16", "#22c55e", "#14b8a6",
+	"#06b6d4", "#3b82f6", "#6366f1", "#8b5cf6", "#d946ef", "#ec4899",
+}
+
+// AvatarURLs are signed links to a user's avatar in each size. They serve
+// the uploaded image, or a generated picture with the user's initials.
+type AvatarURLs struct {
+	Small  string `json:"small"`
+	Medium string `json:"medium"`
+	Large  string `json:"large"`
+}
+
+// DefaultColor picks a colour for a user from their ID, so it stays the same
+// until they choose another
+func DefaultColor(userID string) string {
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	return avatarColors[h.Sum32()%uint32(len(avatarColors))]
+}
+
+// AvatarFileKey is where the avatar stored under key is kept in one size
+func AvatarFileKey(key, size string) string {
+	return key + "-" + size + ".jpg"
+}
+
+func (u *User) AfterFind(tx *gorm.DB) (err error) {
+	u.fillAvatarURLs()
+	return
+}
+
+func (u *User) AfterSave(tx *gorm.DB) (err error) {
+	u.fillAvatarURLs()
+	return
+}
+
+// fillAvatarURLs signs the avatar links of a loaded user. Links expire at a
+// day boundary, at least a day out, so they stay the same long enough for
+// clients to cache the images; the version changes whenever the user does.
+func (u *User) fillAvatarURLs() {
+	if u.ID == "" {
+		return
+	}
+	if u.Color == "" {
+		u.Color = DefaultColor(u.ID)
+	}
+
+	expires := time.Now().UTC().Truncate(24 * time.Hour).Add(48 * time.Hour)
+	version := strconv.FormatInt(u.UpdatedAt.Unix(), 10)
+	base := strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/")
+	link := func(size string) string {
+		path := "/files/avatars/" + u.ID + "/" + size
+		return base + path + "?v=" + version + "&" + utils.SignPath(path, expires)
+	}
+	u.AvatarURLs = AvatarURLs{
+		Small:  link("small"),
+		Medium: link("medium"),
+		Large:  link("large"),
+	}
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Device is a phone or tablet signed in as a User. A user can have several.
+type Device struct {
+	ID        string     `json:"id" gorm:"primarykey"`
+	UserID    string     `json:"userId" gorm:"not null;index"`
+	DeviceID  string     `json:"deviceId" gorm:"unique;not null"`
+	Name      string     `json:"name"`
+	CreatedAt time.Time  `json:"createdAt"`
+	LastSeen  *time.Time `json:"lastSeen"`
+
+	// Current is set when listing devices to mark the caller's own device
+	Current bool `json:"current" gorm:"-"`
+}
+
+func (d *Device) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return
+}
+
+// BackfillDevices creates a Device row for every user whose original device
+// is not yet in the devices table, so accounts created before multi-device
+// support keep working.
+func BackfillDevices(db *gorm.DB) error {
+	var users []User
+	if err := db.Where("device_id NOT IN (?)", db.Model(&Device{}).Select("device_id")).Find(&users).Error; err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		device := Device{
+			UserID:   user.ID,
+			DeviceID: user.DeviceID,
+			LastSeen: user.LastSeen,
+		}
+		if err := db.Create(&device).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
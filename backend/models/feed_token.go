@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// What a calendar feed includes
+const (
+	FeedScopeUser      = "user"
+	FeedScopeHousehold = "household"
+)
+
+// FeedTokenPrefix starts every calendar feed token
+const FeedTokenPrefix = "htfeed_"
+
+// FeedToken is a secret that lets calendar apps subscribe to a user's or a
+// household's tasks without signing in. The token is part of the feed URL,
+// so only a hash of it is stored and it can be revoked at any time.
+type FeedToken struct {
+	ID          string     `json:"id" gorm:"primarykey"`
+	UserID      string     `json:"userId" gorm:"not null;index"`
+	HouseholdID string     `json:"householdId" gorm:"not null"`
+	Scope       string     `json:"scope" gorm:"not null"`
+	Name        string     `json:"name" gorm:"not null"`
+	TokenPrefix string     `json:"tokenPrefix"`
+	TokenHash   string     `json:"-" gorm:"unique;not null"`
+	LastUsedAt  *time.Time `json:"lastUsedAt"`
+	RevokedAt   *time.Time `json:"revokedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func (t *FeedToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return
+}
@@ -1,20 +1,26 @@
 package models
 
 import (
+	"sync/atomic"
 	"time"
 
+	"household-todo-backend/utils"
+
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Household struct {
-	ID         string    `json:"id" gorm:"primarykey"`
-	Name       string    `json:"name" gorm:"not null"`
-	InviteCode string    `json:"inviteCode" gorm:"unique;not null"`
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
-	Users      []User    `json:"users" gorm:"foreignKey:HouseholdID"`
-	Tasks      []Task    `json:"tasks" gorm:"foreignKey:HouseholdID"`
+	ID         string `json:"id" gorm:"primarykey"`
+	Name       string `json:"name" gorm:"not null"`
+	InviteCode string `json:"inviteCode" gorm:"unique;not null"`
+	// Set while the household still has a code from before invite codes
+	// carried a checksum; only such codes are looked up without one
+	LegacyInviteCode bool      `json:"-" gorm:"not null;default:false"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+	Users            []User    `json:"users" gorm:"many2many:memberships"`
+	Tasks            []Task    `json:"tasks" gorm:"foreignKey:HouseholdID"`
 }
 
 func (h *Household) BeforeCreate(tx *gorm.DB) (err error) {
@@ -23,3 +29,39 @@ func (h *Household) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// legacyInviteCodes records whether any household had a legacy invite code
+// at startup, so mistyped codes are rejected without a lookup once none do
+var legacyInviteCodes atomic.Bool
+
+// FlagLegacyInviteCodes marks households whose invite code has no valid
+// checksum, so codes handed out before checksums existed keep working
+// until the household refreshes its code.
+func FlagLegacyInviteCodes(db *gorm.DB) error {
+	var households []Household
+	if err := db.Select("id", "invite_code").Where("legacy_invite_code = ?", false).Find(&households).Error; err != nil {
+		return err
+	}
+
+	for _, household := range households {
+		if utils.ValidInviteCode(household.InviteCode) {
+			continue
+		}
+		if err := db.Model(&Household{}).Where("id = ?", household.ID).Update("legacy_invite_code", true).Error; err != nil {
+			return err
+		}
+	}
+
+	var count int64
+	if err := db.Model(&Household{}).Where("legacy_invite_code = ?", true).Count(&count).Error; err != nil {
+		return err
+	}
+	legacyInviteCodes.Store(count > 0)
+	return nil
+}
+
+// HasLegacyInviteCodes reports whether codes without a checksum may still
+// belong to a household
+func HasLegacyInviteCodes() bool {
+	return legacyInviteCodes.Load()
+}
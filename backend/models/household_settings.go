@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Defaults for households that have not changed their settings
+const (
+	DefaultTimezone              = "UTC"
+	DefaultWeekStart             = time.Monday
+	DefaultLocale                = "en-US"
+	DefaultReminderOffsetMinutes = 60
+)
+
+// HouseholdSettings holds the preferences shared by everyone in a household.
+// Server-side date calculations such as overdue checks use Timezone rather
+// than UTC so every device agrees on what "today" is.
+type HouseholdSettings struct {
+	HouseholdID           string       `json:"householdId" gorm:"primarykey"`
+	Timezone              string       `json:"timezone" gorm:"not null"`
+	WeekStart             time.Weekday `json:"weekStart" gorm:"not null"`
+	Locale                string       `json:"locale" gorm:"not null"`
+	DefaultCategory       TaskCategory `json:"defaultCategory" gorm:"not null"`
+	DefaultReminderOffset int          `json:"defaultReminderOffset" gorm:"not null"`             // Minutes before the due date
+	EnforceDependencies   bool         `json:"enforceDependencies" gorm:"not null;default:false"` // Refuse to complete tasks with open blockers
+	UpdatedAt             time.Time    `json:"updatedAt"`
+}
+
+// GetHouseholdSettings returns the household's settings, or the defaults if
+// it has never saved any
+func GetHouseholdSettings(db *gorm.DB, householdID string) HouseholdSettings {
+	settings := HouseholdSettings{
+		HouseholdID:           householdID,
+		Timezone:              DefaultTimezone,
+		WeekStart:             DefaultWeekStart,
+		Locale:                DefaultLocale,
+		DefaultCategory:       General,
+		DefaultReminderOffset: DefaultReminderOffsetMinutes,
+	}
+	db.Where("household_id = ?", householdID).Limit(1).Find(&settings)
+	return settings
+}
+
+// Location returns the household's time zone, falling back to UTC if the
+// stored name is not known to this server
+func (s HouseholdSettings) Location() *time.Location {
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// StartOfDay returns midnight of the household's local day containing t
+func (s HouseholdSettings) StartOfDay(t time.Time) time.Time {
+	local := t.In(s.Location())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+}
+
+// StartOfWeek returns midnight of the first day of the household's local
+// week containing t
+func (s HouseholdSettings) StartOfWeek(t time.Time) time.Time {
+	day := s.StartOfDay(t)
+	offset := (int(day.Weekday()) - int(s.WeekStart) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// IsOverdue reports whether a task due at dueDate is overdue at now. A task
+// becomes overdue once the household's local day it was due on has ended.
+func (s HouseholdSettings) IsOverdue(dueDate time.Time, now time.Time) bool {
+	return dueDate.Before(s.StartOfDay(now))
+}
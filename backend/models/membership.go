@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Membership roles
+const (
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// Membership links a user to a household they belong to. A user can be a
+// member of several households; User.HouseholdID is the one they use by default.
+type Membership struct {
+	ID          string     `json:"id" gorm:"primarykey"`
+	UserID      string     `json:"userId" gorm:"not null;uniqueIndex:idx_membership_user_household"`
+	HouseholdID string     `json:"householdId" gorm:"not null;uniqueIndex:idx_membership_user_household;index"`
+	Role        string     `json:"role" gorm:"not null;default:member"`
+	LastSeenAt  *time.Time `json:"lastSeenAt"`
+	AwayUntil   *time.Time `json:"awayUntil"` // Not given new tasks until then; see User.IsAway
+	CreatedAt   time.Time  `json:"createdAt"`
+
+	// Relationships
+	Household Household `json:"household" gorm:"foreignKey:HouseholdID"`
+}
+
+func (m *Membership) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == "" {
+		m.ID = uuid.New().String()
+	}
+	return
+}
+
+// IsMember reports whether the user belongs to the household
+func IsMember(db *gorm.DB, userID, householdID string) bool {
+	var count int64
+	db.Model(&Membership{}).Where("user_id = ? AND household_id = ?", userID, householdID).Count(&count)
+	return count > 0
+}
+
+// IsAdmin reports whether the user administers the household
+func IsAdmin(db *gorm.DB, userID, householdID string) bool {
+	var count int64
+	db.Model(&Membership{}).Where("user_id = ? AND household_id = ? AND role = ?", userID, householdID, RoleAdmin).Count(&count)
+	return count > 0
+}
+
+// HouseholdMembers returns the users who belong to a household along with
+// their role and availability in it
+func HouseholdMembers(db *gorm.DB, householdID string) ([]User, error) {
+	var users []User
+	err := db.Select("users.*, memberships.role AS role, memberships.away_until AS away_until").
+		Joins("JOIN memberships ON memberships.user_id = users.id").
+		Where("memberships.household_id = ?", householdID).
+		Order("memberships.created_at").
+		Find(&users).Error
+	return users, err
+}
+
+// BackfillMemberships gives every user a membership in their household, so
+// data from before multi-household support keeps working. The earliest user
+// of each household becomes its admin.
+func BackfillMemberships(db *gorm.DB) error {
+	var users []User
+	if err := db.Where("id NOT IN (?)", db.Model(&Membership{}).Select("user_id")).
+		Order("created_at").Find(&users).Error; err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		role := RoleMember
+		var count int64
+		db.Model(&Membership{}).Where("household_id = ?", user.HouseholdID).Count(&count)
+		if count == 0 {
+			role = RoleAdmin
+		}
+
+		membership := Membership{
+			UserID:      user.ID,
+			HouseholdID: user.HouseholdID,
+			Role:        role,
+			LastSeenAt:  user.LastSeen,
+		}
+		if err := db.Create(&membership).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Notification types
+const (
+	NotificationTaskUnblocked     = "task.unblocked"
+	NotificationReviewRequested   = "task.review_requested"
+	NotificationTaskReviewed      = "task.reviewed"
+	NotificationRewardRequested   = "reward.requested"
+	NotificationRedemptionDecided = "reward.decided"
+)
+
+// Notification tells a user about something that happened in one of their
+// households
+type Notification struct {
+	ID           string     `json:"id" gorm:"primarykey"`
+	UserID       string     `json:"userId" gorm:"not null;index"`
+	HouseholdID  string     `json:"householdId" gorm:"not null;index"`
+	Type         string     `json:"type" gorm:"not null"`
+	TaskID       *string    `json:"taskId"`
+	RedemptionID *string    `json:"redemptionId"`
+	Message      string     `json:"message"`
+	ReadAt       *time.Time `json:"readAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+func (n *Notification) BeforeCreate(tx *gorm.DB) (err error) {
+	if n.ID == "" {
+		n.ID = uuid.New().String()
+	}
+	return
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PairingCode is a short-lived, single-use code that lets a new device sign
+// in as an existing user. Only a hash of the code is stored.
+type PairingCode struct {
+	ID               string     `json:"id" gorm:"primarykey"`
+	UserID           string     `json:"userId" gorm:"not null;index"`
+	CodeHash         string     `json:"-" gorm:"unique;not null"`
+	ExpiresAt        time.Time  `json:"expiresAt"`
+	RedeemedAt       *time.Time `json:"redeemedAt"`
+	RedeemedDeviceID *string    `json:"redeemedDeviceId"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+func (p *PairingCode) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Scopes a personal access token can be granted
+const (
+	ScopeTasksRead     = "tasks:read"
+	ScopeTasksWrite    = "tasks:write"
+	ScopeHouseholdRead = "household:read"
+)
+
+// TokenScopes lists every valid scope
+var TokenScopes = []string{ScopeTasksRead, ScopeTasksWrite, ScopeHouseholdRead}
+
+// PersonalAccessTokenPrefix starts every personal access token, which lets
+// AuthMiddleware tell them apart from device JWTs
+const PersonalAccessTokenPrefix = "htpat_"
+
+// PersonalAccessToken lets scripts and shared displays call the API on a
+// user's behalf with a limited set of scopes. Only a hash of the token is stored.
+type PersonalAccessToken struct {
+	ID          string     `json:"id" gorm:"primarykey"`
+	UserID      string     `json:"userId" gorm:"not null;index"`
+	HouseholdID string     `json:"householdId" gorm:"not null"`
+	Name        string     `json:"name" gorm:"not null"`
+	Scopes      []string   `json:"scopes" gorm:"serializer:json"`
+	TokenPrefix string     `json:"tokenPrefix"`
+	TokenHash   string     `json:"-" gorm:"unique;not null"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt"`
+	RevokedAt   *time.Time `json:"revokedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func (t *PersonalAccessToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return
+}
+
+// HasScope reports whether the token was granted scope
+func (t *PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
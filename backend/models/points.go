@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Points ledger entry kinds
+const (
+	PointsTaskCompleted  = "task.completed"
+	PointsTaskReopened   = "task.reopened"
+	PointsRewardRedeemed = "reward.redeemed"
+	PointsRewardRefunded = "reward.refunded"
+)
+
+// MaxTaskPoints is the most effort points a single task can be worth
+const MaxTaskPoints = 1000
+
+// PointsEntry is one line of a household's points ledger. Entries are never
+// changed or deleted; a completion that is undone gets a reversing entry, so
+// balances, streaks and leaderboards can always be recomputed from history.
+type PointsEntry struct {
+	ID          string    `json:"id" gorm:"primarykey"`
+	HouseholdID string    `json:"householdId" gorm:"not null;index"`
+	UserID      string    `json:"userId" gorm:"not null;index"`
+	Kind        string    `json:"kind" gorm:"not null"`
+	Points      int       `json:"points" gorm:"not null"` // Negative for reversals and spending
+	TaskID      *string   `json:"taskId" gorm:"index"`
+	ReversesID  *string   `json:"reversesId" gorm:"index"` // Entry this one cancels
+	Detail      string    `json:"detail"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"index"`
+}
+
+func (e *PointsEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return
+}
+
+// CreditTaskCompletion records that userID completed a task, crediting its
+// points. Tasks worth no points are recorded too, since completions drive
+// streaks.
+func CreditTaskCompletion(tx *gorm.DB, task *Task, userID string) error {
+	taskID := task.ID
+	return tx.Create(&PointsEntry{
+		HouseholdID: task.HouseholdID,
+		UserID:      userID,
+		Kind:        PointsTaskCompleted,
+		Points:      task.Points,
+		TaskID:      &taskID,
+		Detail:      task.Title,
+	}).Error
+}
+
+// ReverseTaskCompletion cancels every completion of a task that has not been
+// reversed yet, for when the task is marked as not done again
+func ReverseTaskCompletion(tx *gorm.DB, task *Task) error {
+	var credits []PointsEntry
+	if err := tx.Scopes(ValidCompletions).Where("task_id = ?", task.ID).Find(&credits).Error; err != nil {
+		return err
+	}
+	for _, credit := range credits {
+		creditID := credit.ID
+		if err := tx.Create(&PointsEntry{
+			HouseholdID: credit.HouseholdID,
+			UserID:      credit.UserID,
+			Kind:        PointsTaskReopened,
+			Points:      -credit.Points,
+			TaskID:      credit.TaskID,
+			ReversesID:  &creditID,
+			Detail:      task.Title,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidCompletions scopes a query to completion entries that have not been
+// reversed
+func ValidCompletions(db *gorm.DB) *gorm.DB {
+	reversed := db.Session(&gorm.Session{NewDB: true}).Model(&PointsEntry{}).
+		Select("reverses_id").
+		Where("reverses_id IS NOT NULL")
+	return db.Where("kind = ? AND id NOT IN (?)", PointsTaskCompleted, reversed)
+}
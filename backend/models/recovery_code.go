@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RecoveryCode is a one-time code that lets a new device reclaim a user
+// account after the original device is lost. Only a hash is stored.
+type RecoveryCode struct {
+	ID        string     `json:"id" gorm:"primarykey"`
+	UserID    string     `json:"userId" gorm:"not null;index"`
+	CodeHash  string     `json:"-" gorm:"unique;not null"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (r *RecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return
+}
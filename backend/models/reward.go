@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Redemption statuses
+const (
+	RedemptionPending   = "pending"
+	RedemptionApproved  = "approved"
+	RedemptionRejected  = "rejected"
+	RedemptionCancelled = "cancelled"
+)
+
+// MaxRewardCost is the most points a single reward can cost
+const MaxRewardCost = 100000
+
+// Reward is something in a household's rewards catalog that members can
+// spend their points on
+type Reward struct {
+	ID           string    `json:"id" gorm:"primarykey"`
+	HouseholdID  string    `json:"householdId" gorm:"not null;index"`
+	Name         string    `json:"name" gorm:"not null"`
+	Description  string    `json:"description"`
+	Cost         int       `json:"cost" gorm:"not null"`
+	Stock        *int      `json:"stock"`                               // Nil means unlimited
+	RedeemableBy []string  `json:"redeemableBy" gorm:"serializer:json"` // Empty means every member
+	CreatorID    string    `json:"creatorId" gorm:"not null"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func (r *Reward) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return
+}
+
+// CanRedeem reports whether the reward is offered to the user
+func (r *Reward) CanRedeem(userID string) bool {
+	if len(r.RedeemableBy) == 0 {
+		return true
+	}
+	for _, id := range r.RedeemableBy {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// RewardRedemption is a member's request to spend points on a reward. The
+// points are debited when the request is made and refunded if it is
+// rejected or cancelled. The reward's name and cost are copied so history
+// survives the reward being changed or deleted.
+type RewardRedemption struct {
+	ID          string     `json:"id" gorm:"primarykey"`
+	HouseholdID string     `json:"householdId" gorm:"not null;index"`
+	RewardID    string     `json:"rewardId" gorm:"not null;index"`
+	RewardName  string     `json:"rewardName" gorm:"not null"`
+	UserID      string     `json:"userId" gorm:"not null;index"`
+	Cost        int        `json:"cost" gorm:"not null"`
+	Status      string     `json:"status" gorm:"not null;default:pending;index"`
+	DebitID     string     `json:"-" gorm:"not null"` // Ledger entry that paid for it
+	ReviewNote  string     `json:"reviewNote"`
+	ReviewerID  *string    `json:"reviewerId"`
+	ReviewedAt  *time.Time `json:"reviewedAt"`
+	CreatedAt   time.Time  `json:"createdAt" gorm:"index"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+
+	// Relationships
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+func (r *RewardRedemption) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return
+}
+
+// PointsBalance is what a member has left to spend in a household: the sum
+// of everything they have earned, spent and been refunded
+func PointsBalance(db *gorm.DB, householdID, userID string) (int, error) {
+	var balance int
+	err := db.Model(&PointsEntry{}).
+		Select("COALESCE(SUM(points), 0)").
+		Where("household_id = ? AND user_id = ?", householdID, userID).
+		Scan(&balance).Error
+	return balance, err
+}
+
+// ReleaseRedemption undoes a pending redemption's debit and gives back the
+// unit of stock it held. The caller sets the redemption's final status.
+func ReleaseRedemption(tx *gorm.DB, redemption *RewardRedemption) error {
+	debitID := redemption.DebitID
+	if err := tx.Create(&PointsEntry{
+		HouseholdID: redemption.HouseholdID,
+		UserID:      redemption.UserID,
+		Kind:        PointsRewardRefunded,
+		Points:      redemption.Cost,
+		ReversesID:  &debitID,
+		Detail:      redemption.RewardName,
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&Reward{}).
+		Where("id = ? AND stock IS NOT NULL", redemption.RewardID).
+		Update("stock", gorm.Expr("stock + 1")).Error
+}
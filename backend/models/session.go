@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session is one sign-in of a device. Access tokens carry its ID as their jti
+// claim, and its refresh tokens form a single rotation family, so revoking
+// the session signs the device out.
+type Session struct {
+	ID            string     `json:"id" gorm:"primarykey"`
+	UserID        string     `json:"userId" gorm:"not null;index"`
+	HouseholdID   string     `json:"householdId" gorm:"not null"`
+	DeviceID      string     `json:"deviceId" gorm:"not null;index"`
+	UserAgent     string     `json:"userAgent"`
+	IPAddress     string     `json:"ipAddress"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	LastUsedAt    *time.Time `json:"lastUsedAt"`
+	RevokedAt     *time.Time `json:"revokedAt"`
+	RevokedReason string     `json:"revokedReason"`
+
+	// Set when listing sessions
+	DeviceLabel string `json:"deviceLabel" gorm:"-"`
+	Current     bool   `json:"current" gorm:"-"`
+}
+
+func (s *Session) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.ID == "" {
+		s.ID = uuid.New().String()
+	}
+	return
+}
+
+// RefreshToken is a single-use token that can be exchanged for a new access
+// token. Only a hash of the token is stored.
+type RefreshToken struct {
+	ID        string     `json:"id" gorm:"primarykey"`
+	SessionID string     `json:"sessionId" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"unique;not null"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+func (r *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return
+}
@@ -16,12 +16,49 @@ const (
 	General  TaskCategory = "GENERAL"
 )
 
+// Valid reports whether c is one of the known categories
+func (c TaskCategory) Valid() bool {
+	switch c {
+	case Chores, Shopping, Work, General:
+		return true
+	}
+	return false
+}
+
+type TaskPriority string
+
+const (
+	PriorityLow    TaskPriority = "LOW"
+	PriorityNormal TaskPriority = "NORMAL"
+	PriorityHigh   TaskPriority = "HIGH"
+)
+
+// Valid reports whether p is one of the known priorities
+func (p TaskPriority) Valid() bool {
+	switch p {
+	case PriorityLow, PriorityNormal, PriorityHigh:
+		return true
+	}
+	return false
+}
+
+// Review states of a task that requires verification
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
 type Task struct {
 	ID          string       `json:"id" gorm:"primarykey"`
 	Title       string       `json:"title" gorm:"not null"`
 	Description string       `json:"description"`
 	Category    TaskCategory `json:"category" gorm:"default:GENERAL"`
+	Priority    TaskPriority `json:"priority" gorm:"default:NORMAL"`
+	Points      int          `json:"points" gorm:"not null;default:0"`          // Effort points credited to whoever completes it
+	Estimate    int          `json:"estimateMinutes" gorm:"not null;default:0"` // Expected effort in minutes, 0 for none; see TimeEntry
 	DueDate     *time.Time   `json:"dueDate"`
+	Recurrence  string       `json:"recurrence"` // RFC 5545 RRULE, e.g. FREQ=WEEKLY;BYDAY=MO; repeats from DueDate
 	Completed   bool         `json:"completed" gorm:"default:false"`
 	CreatorID   string       `json:"creatorId" gorm:"not null"`
 	HouseholdID string       `json:"householdId" gorm:"not null"`
@@ -30,10 +67,35 @@ type Task struct {
 	CompletedAt *time.Time   `json:"completedAt"`
 	CompletedBy *string      `json:"completedBy"`
 
+	// Tasks that require verification are only completed once their
+	// reviewer, or an admin when there is none, approves them
+	RequiresVerification bool       `json:"requiresVerification" gorm:"not null;default:false"`
+	ReviewerID           *string    `json:"reviewerId"`
+	ReviewStatus         string     `json:"reviewStatus"` // "", pending, approved or rejected
+	SubmittedBy          *string    `json:"submittedBy"`
+	SubmittedAt          *time.Time `json:"submittedAt"`
+	ReviewedBy           *string    `json:"reviewedBy"`
+	ReviewedAt           *time.Time `json:"reviewedAt"`
+	ReviewNote           string     `json:"reviewNote"`
+
+	// Set for tasks created over CalDAV, which keep the UID and resource
+	// name their client chose
+	CalendarUID  string `json:"-"`
+	CalendarName string `json:"-" gorm:"index"`
+
+	// Computed in the household's time zone; not stored
+	Overdue  bool `json:"overdue" gorm:"-"`
+	DueToday bool `json:"dueToday" gorm:"-"`
+
+	// Computed from task dependencies; see LoadDependencies
+	DependsOn []string `json:"dependsOn" gorm:"-"`
+	Blocked   bool     `json:"blocked" gorm:"-"`
+
 	// Relationships
-	Creator     User             `json:"creator" gorm:"foreignKey:CreatorID"`
-	Household   Household        `json:"household" gorm:"foreignKey:HouseholdID"`
-	Assignments []TaskAssignment `json:"assignments" gorm:"foreignKey:TaskID"`
+	Creator     User                `json:"creator" gorm:"foreignKey:CreatorID"`
+	Household   Household           `json:"household" gorm:"foreignKey:HouseholdID"`
+	Assignments []TaskAssignment    `json:"assignments" gorm:"foreignKey:TaskID"`
+	Checklist   []TaskChecklistItem `json:"checklist" gorm:"foreignKey:TaskID"`
 }
 
 func (t *Task) BeforeCreate(tx *gorm.DB) (err error) {
@@ -42,3 +104,12 @@ func (t *Task) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// CalendarResourceName is the name of a task's calendar object resource in
+// its household's CalDAV collection
+func (t *Task) CalendarResourceName() string {
+	if t.CalendarName != "" {
+		return t.CalendarName
+	}
+	return t.ID + ".ics"
+}
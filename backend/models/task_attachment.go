@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Limits on attachments
+const (
+	MaxAttachmentSize     = 10 << 20  // Bytes per file
+	MaxTaskAttachments    = 20        // Files per task
+	HouseholdStorageQuota = 250 << 20 // Bytes per household, not counting thumbnails
+)
+
+// TaskAttachment is a file attached to a task, such as a receipt or a photo
+// showing a chore was done. The file itself lives in storage under
+// StorageKey; images also get a JPEG thumbnail.
+type TaskAttachment struct {
+	ID           string    `json:"id" gorm:"primarykey"`
+	TaskID       string    `json:"taskId" gorm:"not null;index"`
+	HouseholdID  string    `json:"householdId" gorm:"not null;index"`
+	UploaderID   string    `json:"uploaderId" gorm:"not null"`
+	FileName     string    `json:"fileName"`
+	ContentType  string    `json:"contentType" gorm:"not null"` // Sniffed from the content, not taken from the client
+	Size         int64     `json:"size" gorm:"not null"`
+	Width        int       `json:"width,omitempty"` // Images only
+	Height       int       `json:"height,omitempty"`
+	StorageKey   string    `json:"-" gorm:"not null"`
+	ThumbnailKey string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+
+	// Signed download links, filled in per response; not stored
+	URL          string `json:"url" gorm:"-"`
+	ThumbnailURL string `json:"thumbnailUrl,omitempty" gorm:"-"`
+
+	// Relationships
+	Uploader User `json:"uploader" gorm:"foreignKey:UploaderID"`
+}
+
+func (a *TaskAttachment) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return
+}
+
+// HouseholdStorageUsed is how many bytes of attachments a household keeps
+func HouseholdStorageUsed(db *gorm.DB, householdID string) (int64, error) {
+	var used int64
+	err := db.Model(&TaskAttachment{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where("household_id = ?", householdID).
+		Scan(&used).Error
+	return used, err
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskChecklistItem is a step of a task that can be ticked off on its own
+type TaskChecklistItem struct {
+	ID        string    `json:"id" gorm:"primarykey"`
+	TaskID    string    `json:"taskId" gorm:"not null;index"`
+	Position  int       `json:"position"`
+	Title     string    `json:"title" gorm:"not null"`
+	Done      bool      `json:"done" gorm:"default:false"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (i *TaskChecklistItem) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return
+}
+
+// CreateChecklist adds unticked checklist items to a task in the given order
+func CreateChecklist(tx *gorm.DB, taskID string, titles []string) error {
+	for i, title := range titles {
+		if err := tx.Create(&TaskChecklistItem{TaskID: taskID, Position: i, Title: title}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskDependency says a task cannot be started until its blocker is done,
+// e.g. "mop floor" waits for "vacuum floor". Both tasks belong to the same
+// household.
+type TaskDependency struct {
+	ID          string    `json:"id" gorm:"primarykey"`
+	TaskID      string    `json:"taskId" gorm:"not null;index"`
+	BlockerID   string    `json:"blockerId" gorm:"not null;index"`
+	HouseholdID string    `json:"householdId" gorm:"not null;index"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (d *TaskDependency) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return
+}
+
+// OpenBlockers returns the IDs of the incomplete tasks a task waits on
+func OpenBlockers(db *gorm.DB, taskID string) ([]string, error) {
+	var ids []string
+	err := db.Model(&TaskDependency{}).
+		Joins("JOIN tasks ON tasks.id = task_dependencies.blocker_id").
+		Where("task_dependencies.task_id = ? AND tasks.completed = ?", taskID, false).
+		Pluck("task_dependencies.blocker_id", &ids).Error
+	return ids, err
+}
+
+// DependsOnTransitively reports whether taskID waits on blockerID directly
+// or through other tasks. Linking blockerID to wait on taskID would then
+// create a cycle.
+func DependsOnTransitively(db *gorm.DB, taskID, blockerID string) (bool, error) {
+	seen := map[string]bool{taskID: true}
+	queue := []string{taskID}
+	for len(queue) > 0 {
+		var next []string
+		if err := db.Model(&TaskDependency{}).Where("task_id IN ?", queue).Pluck("blocker_id", &next).Error; err != nil {
+			return false, err
+		}
+		queue = queue[:0]
+		for _, id := range next {
+			if id == blockerID {
+				return true, nil
+			}
+			if !seen[id] {
+				seen[id] = true
+				queue = append(queue, id)
+			}
+		}
+	}
+	return false, nil
+}
+
+// LoadDependencies fills in DependsOn and Blocked for each task
+func LoadDependencies(db *gorm.DB, tasks []Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var links []struct {
+		TaskID    string
+		BlockerID string
+		Completed bool
+	}
+	if err := db.Model(&TaskDependency{}).
+		Select("task_dependencies.task_id, task_dependencies.blocker_id, tasks.completed").
+		Joins("JOIN tasks ON tasks.id = task_dependencies.blocker_id").
+		Where("task_dependencies.task_id IN ?", ids).
+		Order("task_dependencies.created_at").
+		Scan(&links).Error; err != nil {
+		return err
+	}
+
+	index := make(map[string]int, len(tasks))
+	for i := range tasks {
+		index[tasks[i].ID] = i
+		tasks[i].DependsOn = []string{}
+		tasks[i].Blocked = false
+	}
+	for _, link := range links {
+		task := &tasks[index[link.TaskID]]
+		task.DependsOn = append(task.DependsOn, link.BlockerID)
+		if !link.Completed && !task.Completed {
+			task.Blocked = true
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDependsOnTransitively(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&TaskDependency{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each pair is task, blocker: a waits on b, which waits on c, and so on.
+	// f joins the chain at b, and p and q wait on each other.
+	links := [][2]string{
+		{"a", "b"}, {"b", "c"}, {"c", "d"}, {"a", "e"},
+		{"f", "b"},
+		{"x", "y"},
+		{"p", "q"}, {"q", "p"},
+	}
+	for _, link := range links {
+		if err := db.Create(&TaskDependency{TaskID: link[0], BlockerID: link[1], HouseholdID: "house-1"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		task, blocker string
+		want          bool
+	}{
+		{"a", "b", true},
+		{"a", "d", true},
+		{"a", "e", true},
+		{"f", "d", true},
+		{"b", "a", false},
+		{"d", "a", false},
+		{"e", "b", false},
+		{"a", "y", false},
+		{"a", "f", false},
+		{"unlinked", "a", false},
+		// Existing cycles must not loop forever
+		{"p", "q", true},
+		{"p", "a", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.task+" on "+tt.blocker, func(t *testing.T) {
+			got, err := DependsOnTransitively(db, tt.task, tt.blocker)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("DependsOnTransitively(%s, %s) = %v, want %v", tt.task, tt.blocker, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskTemplate is a reusable set of tasks, such as everything to do before
+// a holiday trip. A template with one item stands for a single task.
+type TaskTemplate struct {
+	ID          string    `json:"id" gorm:"primarykey"`
+	HouseholdID string    `json:"householdId" gorm:"not null;index"`
+	Name        string    `json:"name" gorm:"not null"`
+	Description string    `json:"description"`
+	StarterPack string    `json:"starterPack,omitempty"` // Slug of the built-in pack it was installed from
+	CreatorID   string    `json:"creatorId" gorm:"not null"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+
+	// Relationships
+	Items []TaskTemplateItem `json:"items" gorm:"foreignKey:TemplateID"`
+}
+
+func (t *TaskTemplate) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return
+}
+
+// TaskTemplateItem describes one task a template creates. Due dates are
+// relative to the anchor date the template is used with.
+type TaskTemplateItem struct {
+	ID            string       `json:"id" gorm:"primarykey"`
+	TemplateID    string       `json:"-" gorm:"not null;index"`
+	Position      int          `json:"position"`
+	Title         string       `json:"title" gorm:"not null"`
+	Description   string       `json:"description"`
+	Category      TaskCategory `json:"category"`
+	Priority      TaskPriority `json:"priority"`
+	Points        int          `json:"points"`
+	Estimate      int          `json:"estimateMinutes"`
+	DueOffsetDays *int         `json:"dueOffsetDays"` // Days after the anchor date, negative for before; nil for no due date
+	DueTime       string       `json:"dueTime"`       // HH:MM in the household timezone; empty for all day
+	Recurrence    string       `json:"recurrence"`
+	AssigneeIDs   []string     `json:"assigneeIds" gorm:"serializer:json"`
+	Checklist     []string     `json:"checklist" gorm:"serializer:json"`
+}
+
+func (i *TaskTemplateItem) BeforeCreate(tx *gorm.DB) (err error) {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	return
+}
+
+// DeleteTaskTemplate removes a template with its items
+func DeleteTaskTemplate(tx *gorm.DB, template *TaskTemplate) error {
+	if err := tx.Where("template_id = ?", template.ID).Delete(&TaskTemplateItem{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(template).Error
+}
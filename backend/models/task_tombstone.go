@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TaskTombstone remembers a deleted task so CalDAV clients syncing changes
+// since an earlier sync token learn that it is gone
+type TaskTombstone struct {
+	ID           string    `json:"id" gorm:"primarykey"`
+	TaskID       string    `json:"taskId" gorm:"not null"`
+	HouseholdID  string    `json:"householdId" gorm:"not null;index"`
+	CalendarName string    `json:"calendarName" gorm:"not null"`
+	DeletedAt    time.Time `json:"deletedAt" gorm:"not null;index"`
+}
+
+func (t *TaskTombstone) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return
+}
+
+// DeleteTask removes a task with its assignments, checklist and dependency
+// links and leaves a tombstone
+func DeleteTask(tx *gorm.DB, task *Task) error {
+	if err := tx.Where("task_id = ?", task.ID).Delete(&TaskAssignment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("task_id = ?", task.ID).Delete(&TaskChecklistItem{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("task_id = ? OR blocker_id = ?", task.ID, task.ID).Delete(&TaskDependency{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Delete(task).Error; err != nil {
+		return err
+	}
+	return tx.Create(&TaskTombstone{
+		TaskID:       task.ID,
+		HouseholdID:  task.HouseholdID,
+		CalendarName: task.CalendarResourceName(),
+		DeletedAt:    time.Now(),
+	}).Error
+}
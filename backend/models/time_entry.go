@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Limits on estimates and time entries
+const (
+	MaxEstimateMinutes = 7 * 24 * 60 // A week
+	MaxEntryMinutes    = 24 * 60     // Per manually entered entry
+)
+
+// TimeEntry is time someone spent on a task, either measured with a timer
+// or entered afterwards. The task's title and category are copied so reports
+// keep working after the task changes or is deleted.
+type TimeEntry struct {
+	ID          string       `json:"id" gorm:"primarykey"`
+	TaskID      string       `json:"taskId" gorm:"not null;index"`
+	HouseholdID string       `json:"householdId" gorm:"not null;index"`
+	UserID      string       `json:"userId" gorm:"not null;index"`
+	TaskTitle   string       `json:"taskTitle"`
+	Category    TaskCategory `json:"category"`
+	StartedAt   time.Time    `json:"startedAt" gorm:"not null;index"`
+	EndedAt     *time.Time   `json:"endedAt"` // nil while the timer runs
+	Seconds     int          `json:"seconds" gorm:"not null;default:0"`
+	Manual      bool         `json:"manual" gorm:"not null;default:false"`
+	Note        string       `json:"note"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+
+	// Relationships
+	User User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+func (e *TimeEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return
+}
+
+// Running scopes a query to timers that have not been stopped
+func Running(db *gorm.DB) *gorm.DB {
+	return db.Where("ended_at IS NULL")
+}
+
+// StopTimer stops a running timer at the given time and reports whether it
+// was still running
+func StopTimer(tx *gorm.DB, entry *TimeEntry, at time.Time) (bool, error) {
+	seconds := int(at.Sub(entry.StartedAt).Seconds())
+	if seconds < 0 {
+		seconds = 0
+	}
+	result := tx.Model(&TimeEntry{}).
+		Where("id = ? AND ended_at IS NULL", entry.ID).
+		Updates(map[string]interface{}{"ended_at": at, "seconds": seconds})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	entry.EndedAt = &at
+	entry.Seconds = seconds
+	return true, nil
+}
+
+// StopTimers stops every running timer matching a condition, for when the
+// task is finished or deleted or its user leaves
+func StopTimers(tx *gorm.DB, query interface{}, args ...interface{}) error {
+	var running []TimeEntry
+	if err := tx.Scopes(Running).Where(query, args...).Find(&running).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range running {
+		if _, err := StopTimer(tx, &running[i], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -10,22 +10,41 @@ import (
 type User struct {
 	ID          string     `json:"id" gorm:"primarykey"`
 	Name        string     `json:"name" gorm:"not null"`
-	DeviceID    string     `json:"deviceId" gorm:"unique;not null"`
+	DeviceID    string     `json:"deviceId" gorm:"unique;not null"` // Device the account was created on; see Devices
 	HouseholdID string     `json:"householdId" gorm:"not null"`
 	CreatedAt   time.Time  `json:"createdAt"`
 	UpdatedAt   time.Time  `json:"updatedAt"`
 	LastSeen    *time.Time `json:"lastSeen"`
 	IsActive    bool       `json:"isActive" gorm:"default:true"`
+	Role        string     `json:"role,omitempty" gorm:"->;-:migration"`      // Role in the household being listed; see HouseholdMembers
+	AwayUntil   *time.Time `json:"awayUntil,omitempty" gorm:"->;-:migration"` // Availability in the household being listed
+
+	// Profile
+	Color           string     `json:"color"`           // Hex colour such as "#3b82f6"; see DefaultColor
+	Emoji           string     `json:"emoji,omitempty"` // Optional single emoji shown next to the name
+	AvatarKey       string     `json:"-"`               // Storage key prefix of the uploaded avatar, empty for the generated one
+	AvatarUpdatedAt *time.Time `json:"avatarUpdatedAt"` // When the current avatar was uploaded, nil when there is none
+	AvatarURLs      AvatarURLs `json:"avatarUrls" gorm:"-"`
 
 	// Relationships
 	Household       Household        `json:"household" gorm:"foreignKey:HouseholdID"`
 	CreatedTasks    []Task           `json:"createdTasks" gorm:"foreignKey:CreatorID"`
 	TaskAssignments []TaskAssignment `json:"taskAssignments" gorm:"foreignKey:UserID"`
+	Devices         []Device         `json:"devices" gorm:"foreignKey:UserID"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.ID == "" {
 		u.ID = uuid.New().String()
 	}
+	if u.Color == "" {
+		u.Color = DefaultColor(u.ID)
+	}
 	return
 }
+
+// IsAway reports whether a member loaded by HouseholdMembers is unavailable
+// at the given time
+func (u *User) IsAway(at time.Time) bool {
+	return u.AwayUntil != nil && u.AwayUntil.After(at)
+}
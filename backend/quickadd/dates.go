@@ -0,0 +1,369 @@
+package quickadd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Clock times for words like "morning" and "tonight"
+const (
+	morningHour   = 9
+	afternoonHour = 15
+	eveningHour   = 18
+	tonightHour   = 20
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+// weekday also accepts plurals, as in "every mondays" or "on fridays"
+func weekday(word string) (time.Weekday, bool) {
+	if day, ok := weekdays[word]; ok {
+		return day, true
+	}
+	day, ok := weekdays[strings.TrimSuffix(word, "s")]
+	return day, ok
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var clockWords = map[string]int{
+	"morning":   morningHour,
+	"noon":      12,
+	"midday":    12,
+	"afternoon": afternoonHour,
+	"evening":   eveningHour,
+	"midnight":  0,
+}
+
+// today returns the start of the current day in the household's time zone
+func (p *parser) today() time.Time {
+	now := p.opts.Now
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, p.opts.Location)
+}
+
+func (p *parser) setDate(day time.Time) {
+	p.date = &day
+}
+
+// matchDate recognizes a calendar day: today, tomorrow, weekdays, "next
+// week", "next month", ISO and numeric dates, and month names
+func (p *parser) matchDate(i int) int {
+	today := p.today()
+	word := p.word(i)
+
+	switch word {
+	case "today":
+		p.setDate(today)
+		return 1
+	case "tonight":
+		p.setDate(today)
+		p.tonight = true
+		return 1
+	case "tomorrow", "tmrw", "tmr":
+		p.setDate(today.AddDate(0, 0, 1))
+		return 1
+	case "weekend":
+		p.setDate(nextWeekday(today, time.Saturday, true))
+		return 1
+	case "this", "next":
+		next := p.word(i + 1)
+		if day, ok := weekday(next); ok {
+			p.setDate(nextWeekday(today, day, word == "this"))
+			return 2
+		}
+		switch {
+		case next == "weekend":
+			p.setDate(nextWeekday(today, time.Saturday, true))
+			return 2
+		case word == "next" && next == "week":
+			offset := (int(today.Weekday()) - int(p.opts.WeekStart) + 7) % 7
+			p.setDate(today.AddDate(0, 0, 7-offset))
+			return 2
+		case word == "next" && next == "month":
+			p.setDate(time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, p.opts.Location))
+			return 2
+		}
+		return 0
+	}
+
+	if day, ok := weekday(word); ok {
+		p.setDate(nextWeekday(today, day, true))
+		return 1
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", word, p.opts.Location); err == nil {
+		p.setDate(t)
+		return 1
+	}
+
+	if n := p.matchNumericDate(i); n > 0 {
+		return n
+	}
+	return p.matchMonthDate(i)
+}
+
+// nextWeekday returns the next day falling on day, or today if it does and
+// includeToday is set
+func nextWeekday(today time.Time, day time.Weekday, includeToday bool) time.Time {
+	offset := (int(day) - int(today.Weekday()) + 7) % 7
+	if offset == 0 && !includeToday {
+		offset = 7
+	}
+	return today.AddDate(0, 0, offset)
+}
+
+// matchNumericDate reads 14/3, 3/14/2027 or 14.3. in the household's
+// locale: month first for en-US, day first everywhere else
+func (p *parser) matchNumericDate(i int) int {
+	word := strings.TrimSuffix(p.word(i), ".")
+	sep := "/"
+	if !strings.Contains(word, "/") {
+		sep = "."
+	}
+	parts := strings.Split(word, sep)
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0
+	}
+
+	numbers := make([]int, len(parts))
+	for j, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0
+		}
+		numbers[j] = n
+	}
+
+	day, month := numbers[0], numbers[1]
+	if strings.EqualFold(p.opts.Locale, "en-US") && sep == "/" {
+		day, month = month, day
+	}
+	year := -1
+	if len(numbers) == 3 {
+		year = numbers[2]
+		if year < 100 {
+			year += 2000
+		}
+	}
+
+	date, ok := p.dayOfYear(year, month, day)
+	if !ok {
+		return 0
+	}
+	p.setDate(date)
+	return 1
+}
+
+// matchMonthDate reads "march 14", "mar 14th 2027", "14 march" and "14th of
+// march"
+func (p *parser) matchMonthDate(i int) int {
+	if month, ok := months[p.word(i)]; ok {
+		day, ok := ordinal(p.word(i + 1))
+		if !ok {
+			return 0
+		}
+		n := 2
+		year := -1
+		if y, err := strconv.Atoi(p.word(i + 2)); err == nil && y >= 2000 && y < 2200 {
+			year, n = y, 3
+		}
+		date, ok := p.dayOfYear(year, int(month), day)
+		if !ok {
+			return 0
+		}
+		p.setDate(date)
+		return n
+	}
+
+	day, ok := ordinal(p.word(i))
+	if !ok {
+		return 0
+	}
+	n := 1
+	if p.word(i+n) == "of" {
+		n++
+	}
+	month, ok := months[p.word(i+n)]
+	if !ok {
+		return 0
+	}
+	n++
+	year := -1
+	if y, err := strconv.Atoi(p.word(i + n)); err == nil && y >= 2000 && y < 2200 {
+		year = y
+		n++
+	}
+	date, ok := p.dayOfYear(year, int(month), day)
+	if !ok {
+		return 0
+	}
+	p.setDate(date)
+	return n
+}
+
+// dayOfYear builds a date, picking this year or next when year is -1 so the
+// date is not in the past
+func (p *parser) dayOfYear(year, month, day int) (time.Time, bool) {
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, false
+	}
+	explicit := year != -1
+	if !explicit {
+		year = p.opts.Now.Year()
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, p.opts.Location)
+	if date.Day() != day {
+		return time.Time{}, false
+	}
+	if !explicit && date.Before(p.today()) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return date, true
+}
+
+// ordinal reads a day number such as 14 or 14th
+func ordinal(word string) (int, bool) {
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		word = strings.TrimSuffix(word, suffix)
+	}
+	n, err := strconv.Atoi(word)
+	if err != nil || n < 1 || n > 31 {
+		return 0, false
+	}
+	return n, true
+}
+
+// matchRelative reads "in 3 days", "in a week" or "in 2 hours". Hours and
+// minutes give an exact time.
+func (p *parser) matchRelative(i int) int {
+	if p.word(i) != "in" {
+		return 0
+	}
+	amount := 0
+	switch word := p.word(i + 1); word {
+	case "a", "an", "one":
+		amount = 1
+	default:
+		n, err := strconv.Atoi(word)
+		if err != nil || n < 1 || n > 1000 {
+			return 0
+		}
+		amount = n
+	}
+
+	today := p.today()
+	unit := strings.TrimSuffix(p.word(i+2), "s")
+	switch unit {
+	case "minute", "min":
+		t := p.opts.Now.Add(time.Duration(amount) * time.Minute).Truncate(time.Minute)
+		p.instant = &t
+	case "hour", "hr":
+		t := p.opts.Now.Add(time.Duration(amount) * time.Hour).Truncate(time.Minute)
+		p.instant = &t
+	case "day":
+		p.setDate(today.AddDate(0, 0, amount))
+	case "week":
+		p.setDate(today.AddDate(0, 0, 7*amount))
+	case "month":
+		p.setDate(today.AddDate(0, amount, 0))
+	case "year":
+		p.setDate(today.AddDate(amount, 0, 0))
+	default:
+		return 0
+	}
+
+	if p.instant != nil {
+		p.recognize(i, 3, KindTime, p.instant.Format(time.RFC3339))
+	} else {
+		p.recognize(i, 3, KindDate, p.date.Format("2006-01-02"))
+	}
+	return 3
+}
+
+// matchTime reads 7pm, 7 pm, 7:30pm, 19:00, noon and times of day like
+// "morning"
+func (p *parser) matchTime(i int) int {
+	word := p.word(i)
+	if hour, ok := clockWords[word]; ok {
+		p.hour, p.min, p.hasTime = hour, 0, true
+		return 1
+	}
+
+	n := 1
+	suffix := ""
+	for _, s := range []string{"am", "pm", "a.m", "p.m"} {
+		if strings.HasSuffix(word, s) {
+			suffix, word = s[:1], strings.TrimSuffix(word, s)
+			break
+		}
+	}
+	if suffix == "" {
+		switch p.word(i + 1) {
+		case "am", "a.m":
+			suffix, n = "a", 2
+		case "pm", "p.m":
+			suffix, n = "p", 2
+		}
+	}
+
+	hourText, minuteText, hasMinutes := strings.Cut(strings.Replace(word, ".", ":", 1), ":")
+	hour, err := strconv.Atoi(hourText)
+	if err != nil {
+		return 0
+	}
+	minute := 0
+	if hasMinutes {
+		if minute, err = strconv.Atoi(minuteText); err != nil || len(minuteText) != 2 || minute > 59 {
+			return 0
+		}
+	}
+
+	switch {
+	case suffix != "":
+		if hour < 1 || hour > 12 {
+			return 0
+		}
+		hour %= 12
+		if suffix == "p" {
+			hour += 12
+		}
+	case hasMinutes:
+		if hour > 23 {
+			return 0
+		}
+	case i > 0 && p.word(i-1) == "at" && hour <= 23:
+		// "at 7" on its own is taken as a 24-hour clock time
+	default:
+		return 0
+	}
+
+	p.hour, p.min, p.hasTime = hour, minute, true
+	return n
+}
+
+func formatClock(hour, minute int) string {
+	return fmt.Sprintf("%02d:%02d", hour, minute)
+}
@@ -0,0 +1,362 @@
+// Package quickadd turns a line of free text such as
+// "take out bins every tuesday 7pm @Sam #chores !high" into task fields.
+// Anything that is not recognized as a date, time, recurrence, assignee,
+// category or priority becomes the title.
+package quickadd
+
+import (
+	"strings"
+	"time"
+
+	"household-todo-backend/models"
+	"household-todo-backend/utils"
+)
+
+// Kinds of recognized phrase reported in Result.Recognized
+const (
+	KindDate       = "date"
+	KindTime       = "time"
+	KindRecurrence = "recurrence"
+	KindAssignee   = "assignee"
+	KindCategory   = "category"
+	KindPriority   = "priority"
+)
+
+// Member is someone tasks can be assigned to with @name
+type Member struct {
+	ID   string `json:"userId"`
+	Name string `json:"name"`
+}
+
+// Options carries the household context text is parsed in
+type Options struct {
+	Now       time.Time
+	Location  *time.Location
+	WeekStart time.Weekday
+	// Locale decides whether 3/4 is March 4 (en-US) or 3 April (anything else)
+	Locale  string
+	Members []Member
+	// UserID is who @me refers to
+	UserID string
+}
+
+// Phrase is a piece of the input that was recognized
+type Phrase struct {
+	Text  string `json:"text"`
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Result is the breakdown of a parsed line
+type Result struct {
+	Title              string              `json:"title"`
+	Description        string              `json:"description"`
+	DueDate            *time.Time          `json:"dueDate"`
+	AllDay             bool                `json:"allDay"`
+	Recurrence         string              `json:"recurrence"`
+	Category           models.TaskCategory `json:"category,omitempty"`
+	Priority           models.TaskPriority `json:"priority,omitempty"`
+	Assignees          []Member            `json:"assignees"`
+	UnmatchedAssignees []string            `json:"unmatchedAssignees"`
+	Recognized         []Phrase            `json:"recognized"`
+	Warnings           []string            `json:"warnings"`
+}
+
+// connectors are dropped when they introduce a date or time, and trimmed
+// from the ends of the title
+var connectors = map[string]bool{"on": true, "at": true, "by": true, "due": true, "@": true}
+
+// parser holds the state of one Parse call
+type parser struct {
+	opts     Options
+	words    []string // as typed
+	norm     []string // lower case without surrounding punctuation
+	consumed []bool
+	result   Result
+
+	date      *time.Time // calendar day in opts.Location
+	hour, min int
+	hasTime   bool
+	instant   *time.Time // exact moment, e.g. "in 2 hours"
+	tonight   bool       // sets an evening time unless one is given
+	rule      string
+}
+
+// Parse reads a line of text. Text after " // " becomes the description.
+func Parse(text string, opts Options) Result {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	opts.Now = opts.Now.In(opts.Location)
+
+	p := &parser{opts: opts}
+	p.result.Assignees = []Member{}
+	p.result.UnmatchedAssignees = []string{}
+	p.result.Recognized = []Phrase{}
+	p.result.Warnings = []string{}
+
+	if title, description, ok := strings.Cut(text, " // "); ok {
+		text = title
+		p.result.Description = strings.TrimSpace(description)
+	}
+
+	p.words = strings.Fields(text)
+	p.norm = make([]string, len(p.words))
+	p.consumed = make([]bool, len(p.words))
+	for i, word := range p.words {
+		p.norm[i] = strings.Trim(strings.ToLower(word), ",.;:()\"'")
+	}
+
+	for i := 0; i < len(p.words); {
+		if n := p.match(i); n > 0 {
+			for j := i; j < i+n; j++ {
+				p.consumed[j] = true
+			}
+			i += n
+			continue
+		}
+		i++
+	}
+
+	p.resolveDue()
+	p.result.Title = p.title()
+	return p.result
+}
+
+// match tries every matcher at position i and returns how many words the
+// first one that fits consumed
+func (p *parser) match(i int) int {
+	word := p.words[i]
+	switch {
+	case strings.HasPrefix(word, "@") && len(word) > 1:
+		return p.matchAssignee(i)
+	case strings.HasPrefix(word, "#") && len(word) > 1:
+		return p.matchCategory(i)
+	case strings.HasPrefix(word, "!"):
+		return p.matchPriority(i)
+	}
+
+	if p.rule == "" {
+		if n := p.matchRecurrence(i); n > 0 {
+			return n
+		}
+	}
+
+	// "on friday", "at 7pm", "by tomorrow"
+	start := i
+	if connectors[p.norm[i]] && i+1 < len(p.words) {
+		start = i + 1
+	}
+	if p.date == nil && p.instant == nil {
+		if n := p.matchDate(start); n > 0 {
+			p.recognize(i, start-i+n, KindDate, p.date.Format("2006-01-02"))
+			return start - i + n
+		}
+		if n := p.matchRelative(start); n > 0 {
+			return start - i + n
+		}
+	}
+	if !p.hasTime && p.instant == nil {
+		if n := p.matchTime(start); n > 0 {
+			p.recognize(i, start-i+n, KindTime, formatClock(p.hour, p.min))
+			return start - i + n
+		}
+	}
+	return 0
+}
+
+// recognize records the words i..i+n as a phrase of the given kind
+func (p *parser) recognize(i, n int, kind, value string) {
+	p.result.Recognized = append(p.result.Recognized, Phrase{
+		Text:  strings.Join(p.words[i:i+n], " "),
+		Kind:  kind,
+		Value: value,
+	})
+}
+
+// word returns the normalized word at i, or "" past the end
+func (p *parser) word(i int) string {
+	if i < 0 || i >= len(p.norm) {
+		return ""
+	}
+	return p.norm[i]
+}
+
+func (p *parser) matchAssignee(i int) int {
+	name := strings.Trim(p.words[i][1:], ",.;:!?")
+	if strings.EqualFold(name, "me") && p.opts.UserID != "" {
+		for _, member := range p.opts.Members {
+			if member.ID == p.opts.UserID {
+				p.assign(member)
+				p.recognize(i, 1, KindAssignee, member.Name)
+				return 1
+			}
+		}
+	}
+
+	key := strings.ToLower(name)
+	var matches []Member
+	for _, member := range p.opts.Members {
+		full := strings.ToLower(strings.TrimSpace(member.Name))
+		if full == key || strings.ReplaceAll(full, " ", "") == key {
+			matches = []Member{member}
+			break
+		}
+		if first, _, _ := strings.Cut(full, " "); first == key {
+			matches = append(matches, member)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		p.assign(matches[0])
+		p.recognize(i, 1, KindAssignee, matches[0].Name)
+	case 0:
+		p.result.UnmatchedAssignees = append(p.result.UnmatchedAssignees, name)
+		p.result.Warnings = append(p.result.Warnings, "No household member called "+name)
+	default:
+		p.result.UnmatchedAssignees = append(p.result.UnmatchedAssignees, name)
+		p.result.Warnings = append(p.result.Warnings, "More than one member is called "+name+"; use their full name")
+	}
+	return 1
+}
+
+func (p *parser) assign(member Member) {
+	for _, existing := range p.result.Assignees {
+		if existing.ID == member.ID {
+			return
+		}
+	}
+	p.result.Assignees = append(p.result.Assignees, member)
+}
+
+var categoryTags = map[string]models.TaskCategory{
+	"chores":    models.Chores,
+	"chore":     models.Chores,
+	"cleaning":  models.Chores,
+	"shopping":  models.Shopping,
+	"shop":      models.Shopping,
+	"groceries": models.Shopping,
+	"work":      models.Work,
+	"general":   models.General,
+}
+
+func (p *parser) matchCategory(i int) int {
+	tag := strings.Trim(strings.ToLower(p.words[i][1:]), ",.;:!?")
+	category, ok := categoryTags[tag]
+	if !ok {
+		p.result.Warnings = append(p.result.Warnings, "Unknown category #"+tag+" was kept in the title")
+		return 0
+	}
+	p.result.Category = category
+	p.recognize(i, 1, KindCategory, string(category))
+	return 1
+}
+
+var priorityTags = map[string]models.TaskPriority{
+	"!high":   models.PriorityHigh,
+	"!urgent": models.PriorityHigh,
+	"!!!":     models.PriorityHigh,
+	"!1":      models.PriorityHigh,
+	"!normal": models.PriorityNormal,
+	"!medium": models.PriorityNormal,
+	"!!":      models.PriorityNormal,
+	"!2":      models.PriorityNormal,
+	"!low":    models.PriorityLow,
+	"!3":      models.PriorityLow,
+}
+
+func (p *parser) matchPriority(i int) int {
+	priority, ok := priorityTags[strings.TrimRight(strings.ToLower(p.words[i]), ",.;:")]
+	if !ok {
+		return 0
+	}
+	p.result.Priority = priority
+	p.recognize(i, 1, KindPriority, string(priority))
+	return 1
+}
+
+// resolveDue combines the recognized date, time and recurrence into a due
+// date. A time without a date is the next time that clock time comes round;
+// a recurrence without a date starts at its first occurrence from today.
+func (p *parser) resolveDue() {
+	loc := p.opts.Location
+	now := p.opts.Now
+
+	if p.instant != nil {
+		p.result.DueDate = p.instant
+	} else if p.date != nil || p.hasTime || p.rule != "" {
+		if p.tonight && !p.hasTime {
+			p.hour, p.hasTime = tonightHour, true
+		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+		day := today
+		if p.date != nil {
+			day = *p.date
+		}
+
+		at := func(day time.Time) time.Time {
+			return time.Date(day.Year(), day.Month(), day.Day(), p.hour, p.min, 0, 0, loc)
+		}
+		due := at(day)
+
+		if p.date == nil && p.rule != "" {
+			if rule, err := utils.ParseRRule(p.rule, loc); err == nil && !anchored(rule) {
+				// The series can start today, or tomorrow if the time has passed
+				if p.hasTime && !due.After(now) {
+					due = at(today.AddDate(0, 0, 1))
+				}
+			} else if err == nil {
+				// Find the first occurrence that is still ahead, treating
+				// yesterday as the start of the series
+				start := at(today.AddDate(0, 0, -1))
+				for next, ok := rule.Next(start, p.opts.WeekStart); ok; next, ok = rule.Next(next, p.opts.WeekStart) {
+					if !p.hasTime || next.After(now) {
+						due = next
+						break
+					}
+				}
+			}
+		} else if p.date == nil && p.hasTime && !due.After(now) {
+			due = at(today.AddDate(0, 0, 1))
+		}
+
+		p.result.DueDate = &due
+		p.result.AllDay = !p.hasTime
+	}
+
+	if p.rule != "" {
+		if p.result.DueDate == nil {
+			return
+		}
+		rule, err := utils.ParseRRule(p.rule, loc)
+		if err != nil {
+			p.result.Warnings = append(p.result.Warnings, "Could not understand the repeat rule")
+			return
+		}
+		p.result.Recurrence = rule.String()
+	}
+}
+
+// title joins the words nothing else claimed, dropping dangling connectors
+func (p *parser) title() string {
+	var kept []string
+	for i, word := range p.words {
+		if !p.consumed[i] {
+			kept = append(kept, word)
+		}
+	}
+	for len(kept) > 0 && connectors[strings.ToLower(kept[len(kept)-1])] {
+		kept = kept[:len(kept)-1]
+	}
+	for len(kept) > 0 && connectors[strings.ToLower(kept[0])] {
+		kept = kept[1:]
+	}
+	return strings.Join(kept, " ")
+}
+
+// anchored reports whether a rule fixes which days it falls on, rather than
+// repeating from whenever it starts
+func anchored(rule *utils.RRule) bool {
+	return len(rule.ByDay) > 0 || len(rule.ByMonthDay) > 0 || len(rule.ByMonth) > 0
+}
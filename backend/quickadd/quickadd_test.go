@@ -0,0 +1,103 @@
+package quickadd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"household-todo-backend/models"
+)
+
+func TestParse(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	// A Wednesday morning
+	now := time.Date(2026, time.October, 14, 10, 0, 0, 0, loc)
+	members := []Member{
+		{ID: "u1", Name: "Sam Lee"},
+		{ID: "u2", Name: "Alex Kim"},
+		{ID: "u3", Name: "Alex Park"},
+	}
+
+	tests := []struct {
+		text       string
+		locale     string
+		title      string
+		due        string // RFC 3339, empty for no due date
+		allDay     bool
+		recurrence string
+		assignees  []string
+		category   models.TaskCategory
+		priority   models.TaskPriority
+		warnings   int
+	}{
+		{text: "water plants", title: "water plants"},
+		{text: "take out bins every tuesday 7pm @Sam #chores !high", title: "take out bins",
+			due: "2026-10-20T19:00:00+02:00", recurrence: "FREQ=WEEKLY;BYDAY=TU",
+			assignees: []string{"u1"}, category: models.Chores, priority: models.PriorityHigh},
+		{text: "call mum tomorrow at 3pm", title: "call mum", due: "2026-10-15T15:00:00+02:00"},
+		{text: "ship parcel friday", title: "ship parcel", due: "2026-10-16T00:00:00+02:00", allDay: true},
+		{text: "laundry tonight", title: "laundry", due: "2026-10-14T20:00:00+02:00"},
+		{text: "dentist in 2 hours", title: "dentist", due: "2026-10-14T12:00:00+02:00"},
+		{text: "gym at 7", title: "gym", due: "2026-10-15T07:00:00+02:00"},
+		{text: "renew passport next week", title: "renew passport", due: "2026-10-19T00:00:00+02:00", allDay: true},
+		// Day first unless the household uses en-US, and never in the past
+		{text: "pay rent on 3/4", title: "pay rent", due: "2027-04-03T00:00:00+02:00", allDay: true},
+		{text: "pay rent on 3/4", locale: "en-US", title: "pay rent", due: "2027-03-04T00:00:00+02:00", allDay: true},
+		{text: "book flights 2026-12-01 9:30", title: "book flights", due: "2026-12-01T09:30:00+02:00"},
+		{text: "check smoke alarm every 3 months", title: "check smoke alarm",
+			due: "2026-10-14T00:00:00+02:00", allDay: true, recurrence: "FREQ=MONTHLY;INTERVAL=3"},
+		{text: "every morning stretch", title: "stretch", due: "2026-10-15T09:00:00+02:00", recurrence: "FREQ=DAILY"},
+		{text: "pay bills every 15th", title: "pay bills", due: "2026-10-15T00:00:00+02:00", allDay: true,
+			recurrence: "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{text: "mow lawn @me !low", title: "mow lawn", assignees: []string{"u2"}, priority: models.PriorityLow},
+		{text: "buy milk #unknown", title: "buy milk #unknown", warnings: 1},
+		{text: "ask @Alex about car", title: "ask about car", warnings: 1},
+		{text: "ask @AlexPark about car", title: "ask about car", assignees: []string{"u3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale+" "+tt.text, func(t *testing.T) {
+			got := Parse(tt.text, Options{Now: now, Location: loc, WeekStart: time.Monday, Locale: tt.locale, Members: members, UserID: "u2"})
+
+			if got.Title != tt.title {
+				t.Errorf("title = %q, want %q", got.Title, tt.title)
+			}
+			due := ""
+			if got.DueDate != nil {
+				due = got.DueDate.Format(time.RFC3339)
+			}
+			if due != tt.due {
+				t.Errorf("due = %q, want %q", due, tt.due)
+			}
+			if got.AllDay != tt.allDay {
+				t.Errorf("allDay = %v, want %v", got.AllDay, tt.allDay)
+			}
+			if got.Recurrence != tt.recurrence {
+				t.Errorf("recurrence = %q, want %q", got.Recurrence, tt.recurrence)
+			}
+			var assignees []string
+			for _, member := range got.Assignees {
+				assignees = append(assignees, member.ID)
+			}
+			if !reflect.DeepEqual(assignees, tt.assignees) {
+				t.Errorf("assignees = %v, want %v", assignees, tt.assignees)
+			}
+			if got.Category != tt.category {
+				t.Errorf("category = %q, want %q", got.Category, tt.category)
+			}
+			if got.Priority != tt.priority {
+				t.Errorf("priority = %q, want %q", got.Priority, tt.priority)
+			}
+			if len(got.Warnings) != tt.warnings {
+				t.Errorf("warnings = %q, want %d", got.Warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestParseDescription(t *testing.T) {
+	got := Parse("report friday // include the receipts", Options{Now: time.Date(2026, time.October, 14, 10, 0, 0, 0, time.UTC)})
+	if got.Title != "report" || got.Description != "include the receipts" {
+		t.Errorf("title, description = %q, %q", got.Title, got.Description)
+	}
+}
@@ -0,0 +1,129 @@
+package quickadd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+var frequencies = map[string]string{
+	"day":   "DAILY",
+	"week":  "WEEKLY",
+	"month": "MONTHLY",
+	"year":  "YEARLY",
+}
+
+var adverbs = map[string]string{
+	"daily":    "DAILY",
+	"weekly":   "WEEKLY",
+	"monthly":  "MONTHLY",
+	"yearly":   "YEARLY",
+	"annually": "YEARLY",
+}
+
+var ordinals = map[string]int{
+	"first": 1, "1st": 1,
+	"second": 2, "2nd": 2,
+	"third": 3, "3rd": 3,
+	"fourth": 4, "4th": 4,
+	"last": -1,
+}
+
+// matchRecurrence recognizes repeat phrases: daily, every day, every other
+// week, every 3 months, every weekday, every tuesday and friday, every 15th
+// and every first monday. "every morning" repeats daily and leaves the time
+// of day for matchTime.
+func (p *parser) matchRecurrence(i int) int {
+	if freq, ok := adverbs[p.word(i)]; ok {
+		return p.setRule(i, 1, "FREQ="+freq)
+	}
+	if p.word(i) != "every" && p.word(i) != "each" {
+		return 0
+	}
+
+	n := 1
+	interval := 1
+	switch word := p.word(i + n); {
+	case word == "other":
+		interval = 2
+		n++
+	case isNumber(word):
+		interval, _ = strconv.Atoi(word)
+		if interval < 1 || interval > 366 {
+			return 0
+		}
+		n++
+	}
+	rule := func(freq string) string {
+		if interval > 1 {
+			return "FREQ=" + freq + ";INTERVAL=" + strconv.Itoa(interval)
+		}
+		return "FREQ=" + freq
+	}
+
+	word := p.word(i + n)
+	if freq, ok := frequencies[strings.TrimSuffix(word, "s")]; ok {
+		return p.setRule(i, n+1, rule(freq))
+	}
+
+	switch word {
+	case "weekday", "weekdays":
+		return p.setRule(i, n+1, rule("WEEKLY")+";BYDAY=MO,TU,WE,TH,FR")
+	case "weekend", "weekends":
+		return p.setRule(i, n+1, rule("WEEKLY")+";BYDAY=SA,SU")
+	}
+
+	if _, ok := clockWords[word]; ok && interval == 1 {
+		return p.setRule(i, n, "FREQ=DAILY")
+	}
+
+	// every first monday, every last friday
+	if nth, ok := ordinals[word]; ok && interval == 1 {
+		if day, ok := weekday(p.word(i + n + 1)); ok {
+			return p.setRule(i, n+2, "FREQ=MONTHLY;BYDAY="+strconv.Itoa(nth)+weekdayCode(day))
+		}
+	}
+
+	// every 15th
+	if day, ok := ordinal(word); ok && !isNumber(word) && interval == 1 {
+		return p.setRule(i, n+1, "FREQ=MONTHLY;BYMONTHDAY="+strconv.Itoa(day))
+	}
+
+	// every tuesday, every mon, wed and fri
+	var days []string
+	for {
+		day, ok := weekday(p.word(i + n))
+		if !ok {
+			break
+		}
+		days = append(days, weekdayCode(day))
+		n++
+		if sep := p.word(i + n); (sep == "and" || sep == "&") && isWeekday(p.word(i+n+1)) {
+			n++
+		}
+	}
+	if len(days) > 0 {
+		return p.setRule(i, n, rule("WEEKLY")+";BYDAY="+strings.Join(days, ","))
+	}
+	return 0
+}
+
+func (p *parser) setRule(i, n int, rule string) int {
+	p.rule = rule
+	p.recognize(i, n, KindRecurrence, rule)
+	return n
+}
+
+func weekdayCode(day time.Weekday) string {
+	return strings.ToUpper(day.String()[:2])
+}
+
+func isWeekday(word string) bool {
+	_, ok := weekday(word)
+	return ok
+}
+
+func isNumber(word string) bool {
+	_, err := strconv.Atoi(word)
+	return err == nil
+}
@@ -0,0 +1,76 @@
+// Package starterpacks holds the built-in task templates a household can
+// install, such as moving house or weekly cleaning. Each pack is a JSON file
+// in packs/ that is embedded into the binary.
+package starterpacks
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"household-todo-backend/models"
+)
+
+//go:embed packs/*.json
+var files embed.FS
+
+// Pack is a built-in template. Due offsets are days from the anchor date the
+// pack is used with, e.g. moving day or the baby's due date.
+type Pack struct {
+	Slug        string                    `json:"slug"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description"`
+	Items       []models.TaskTemplateItem `json:"items"`
+}
+
+var packs = load()
+
+// load reads every embedded pack. The files ship with the binary, so a
+// broken one is a programming error.
+func load() []Pack {
+	entries, err := files.ReadDir("packs")
+	if err != nil {
+		panic(err)
+	}
+
+	var loaded []Pack
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join("packs", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var pack Pack
+		if err := json.Unmarshal(data, &pack); err != nil {
+			panic(fmt.Sprintf("starter pack %s: %v", entry.Name(), err))
+		}
+		pack.Slug = strings.TrimSuffix(entry.Name(), ".json")
+		if pack.Name == "" || len(pack.Items) == 0 {
+			panic(fmt.Sprintf("starter pack %s needs a name and at least one item", entry.Name()))
+		}
+		for i := range pack.Items {
+			pack.Items[i].Position = i
+		}
+		loaded = append(loaded, pack)
+	}
+
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].Name < loaded[j].Name })
+	return loaded
+}
+
+// All returns the built-in packs sorted by name
+func All() []Pack {
+	return packs
+}
+
+// Get returns the pack with the given slug
+func Get(slug string) (Pack, bool) {
+	for _, pack := range packs {
+		if pack.Slug == slug {
+			return pack, true
+		}
+	}
+	return Pack{}, false
+}
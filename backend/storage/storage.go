@@ -0,0 +1,29 @@
+// Package storage keeps uploaded files such as task attachments. Files are
+// addressed by slash-separated keys chosen by the caller.
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned for keys that hold no file
+var ErrNotFound = errors.New("storage: file not found")
+
+// Storage is a place to keep files. Implementations must be safe for
+// concurrent use.
+type Storage interface {
+	// Put stores data under key, replacing any file already there
+	Put(key string, data []byte) error
+
+	// Open returns the file stored under key
+	Open(key string) (File, error)
+
+	// Delete removes the file stored under key
+	Delete(key string) error
+}
+
+// File is a stored file opened for reading
+type File interface {
+	io.ReadSeekCloser
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Initials returns up to two capital letters for a name: the first letters of
+// its first and last words
+func Initials(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "?"
+	}
+	initials := []rune{[]rune(words[0])[0]}
+	if len(words) > 1 {
+		initials = append(initials, []rune(words[len(words)-1])[0])
+	}
+	return strings.ToUpper(string(initials))
+}
+
+// AvatarSVG draws a square avatar of the given size showing text, such as
+// initials or an emoji, on a background colour. The text is white or near
+// black, whichever reads better on the background.
+func AvatarSVG(text, background string, size int) []byte {
+	foreground := "#ffffff"
+	if luminance(background) > 0.6 {
+		foreground = "#1f2937"
+	}
+	fontSize := 42
+	if len([]rune(text)) > 2 {
+		fontSize = 32
+	}
+
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(text))
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 100 100">`, size, size)
+	fmt.Fprintf(&buf, `<rect width="100" height="100" fill="%s"/>`, background)
+	fmt.Fprintf(&buf, `<text x="50" y="50" dy=".35em" text-anchor="middle" font-family="-apple-system, 'Segoe UI', Roboto, Helvetica, Arial, sans-serif" font-size="%d" font-weight="600" fill="%s">%s</text>`,
+		fontSize, foreground, escaped.String())
+	buf.WriteString(`</svg>`)
+	return buf.Bytes()
+}
+
+// luminance is the relative brightness, from 0 to 1, of a "#rrggbb" colour
+func luminance(hex string) float64 {
+	v, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if err != nil || len(hex) != 7 {
+		return 0
+	}
+	r, g, b := float64(v>>16&0xff), float64(v>>8&0xff), float64(v&0xff)
+	return (0.299*r + 0.587*g + 0.114*b) / 255
+}
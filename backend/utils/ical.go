@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icalLineLimit is the maximum length of a content line in octets, excluding
+// the line break (RFC 5545 section 3.1)
+const icalLineLimit = 75
+
+// ICalBuilder writes iCalendar content lines, folding long lines and
+// terminating each with CRLF
+type ICalBuilder struct {
+	b strings.Builder
+}
+
+// Property writes a property whose value is already in iCalendar form.
+// name may include parameters, e.g. "DTSTART;VALUE=DATE".
+func (w *ICalBuilder) Property(name, value string) {
+	w.line(name + ":" + value)
+}
+
+// Text writes a TEXT property, escaping the value
+func (w *ICalBuilder) Text(name, value string) {
+	w.Property(name, ICalEscape(value))
+}
+
+// Begin opens a component such as VCALENDAR or VTODO
+func (w *ICalBuilder) Begin(component string) {
+	w.Property("BEGIN", component)
+}
+
+// End closes a component
+func (w *ICalBuilder) End(component string) {
+	w.Property("END", component)
+}
+
+// String returns the calendar written so far
+func (w *ICalBuilder) String() string {
+	return w.b.String()
+}
+
+// line folds a content line into chunks of at most 75 octets without
+// splitting UTF-8 sequences. Continuation lines start with a space.
+func (w *ICalBuilder) line(s string) {
+	limit := icalLineLimit
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.b.WriteString(s[:cut])
+		w.b.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space counts towards the next line's length
+		limit = icalLineLimit - 1
+	}
+	w.b.WriteString(s)
+	w.b.WriteString("\r\n")
+}
+
+// ICalEscape escapes a TEXT value
+func ICalEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	).Replace(s)
+}
+
+// ICalParamValue quotes a parameter value such as CN if it contains
+// characters that are not allowed unquoted
+func ICalParamValue(s string) string {
+	s = strings.NewReplacer(`"`, "'", "\r", " ", "\n", " ").Replace(s)
+	if strings.ContainsAny(s, ":;,") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// ICalTime formats a UTC DATE-TIME value
+func ICalTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// ICalDate formats a DATE value from t's calendar day in its own location
+func ICalDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// ICalLocalTime formats a DATE-TIME value as local time for use with TZID
+func ICalLocalTime(t time.Time) string {
+	return t.Format("20060102T150405")
+}
+
+// VTimezone writes a VTIMEZONE component for loc covering the given period.
+// Each offset change in the period gets its own STANDARD or DAYLIGHT
+// observance, so no recurrence rules are needed.
+func (w *ICalBuilder) VTimezone(loc *time.Location, from, to time.Time) {
+	w.Begin("VTIMEZONE")
+	w.Text("TZID", loc.String())
+
+	name, offset := from.In(loc).Zone()
+	w.observance(from.In(loc).IsDST(), from.In(time.FixedZone("", offset)), offset, offset, name)
+
+	// Step through the period and narrow down each change to the second
+	const step = 12 * time.Hour
+	for t := from; t.Before(to); t = t.Add(step) {
+		_, before := t.In(loc).Zone()
+		_, after := t.Add(step).In(loc).Zone()
+		if before == after {
+			continue
+		}
+
+		lo, hi := t, t.Add(step)
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, o := mid.In(loc).Zone(); o == before {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+
+		name, offset := hi.In(loc).Zone()
+		w.observance(hi.In(loc).IsDST(), hi.In(time.FixedZone("", before)), before, offset, name)
+	}
+
+	w.End("VTIMEZONE")
+}
+
+// observance writes one STANDARD or DAYLIGHT sub-component. onset is the
+// moment the observance starts, in the local time in effect before it.
+func (w *ICalBuilder) observance(dst bool, onset time.Time, from, to int, name string) {
+	component := "STANDARD"
+	if dst {
+		component = "DAYLIGHT"
+	}
+	w.Begin(component)
+	w.Property("DTSTART", ICalLocalTime(onset))
+	w.Property("TZOFFSETFROM", icalOffset(from))
+	w.Property("TZOFFSETTO", icalOffset(to))
+	if name != "" {
+		w.Text("TZNAME", name)
+	}
+	w.End(component)
+}
+
+// icalOffset formats a UTC offset in seconds as +hhmm or +hhmmss
+func icalOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	value := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		value += fmt.Sprintf("%02d", seconds%60)
+	}
+	return value
+}
@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ICalProperty is one content line of an iCalendar object
+type ICalProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Text returns the property's value with TEXT escapes removed
+func (p *ICalProperty) Text() string {
+	return ICalUnescape(p.Value)
+}
+
+// ICalComponent is a parsed component such as VCALENDAR or VTODO
+type ICalComponent struct {
+	Name       string
+	Properties []ICalProperty
+	Components []ICalComponent
+}
+
+// Property returns the first property called name, or nil
+func (c *ICalComponent) Property(name string) *ICalProperty {
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// ParseICal parses an iCalendar object, which must consist of a single
+// VCALENDAR component. Lines may end in CRLF or LF.
+func ParseICal(data string) (*ICalComponent, error) {
+	var stack []*ICalComponent
+	var root *ICalComponent
+
+	for _, line := range unfoldICal(data) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prop, err := parseICalLine(line)
+		if err != nil {
+			return nil, err
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			component := &ICalComponent{Name: strings.ToUpper(prop.Value)}
+			if len(stack) == 0 && root != nil {
+				return nil, errors.New("more than one top-level component")
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("unexpected END:%s", prop.Value)
+			}
+			component := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				root = component
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, *component)
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("property %s outside a component", prop.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, prop)
+		}
+	}
+
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing END:%s", stack[len(stack)-1].Name)
+	}
+	if root == nil || root.Name != "VCALENDAR" {
+		return nil, errors.New("not an iCalendar object")
+	}
+	return root, nil
+}
+
+// unfoldICal splits data into content lines, joining folded continuation
+// lines that start with a space or tab
+func unfoldICal(data string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+	}
+	return lines
+}
+
+// parseICalLine splits a content line into name, parameters and value.
+// Parameter values may be quoted and contain ':' or ';' when they are.
+func parseICalLine(line string) (ICalProperty, error) {
+	prop := ICalProperty{Params: make(map[string]string)}
+
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return prop, fmt.Errorf("invalid content line %q", line)
+	}
+	prop.Name = strings.ToUpper(line[:i])
+	rest := line[i:]
+
+	for strings.HasPrefix(rest, ";") {
+		rest = rest[1:]
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			return prop, fmt.Errorf("invalid parameter in %q", line)
+		}
+		name := strings.ToUpper(rest[:eq])
+		rest = rest[eq+1:]
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return prop, fmt.Errorf("unterminated quote in %q", line)
+			}
+			value = rest[1 : end+1]
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, ";:")
+			if end < 0 {
+				return prop, fmt.Errorf("invalid content line %q", line)
+			}
+			value = rest[:end]
+			rest = rest[end:]
+		}
+		prop.Params[name] = value
+	}
+
+	if !strings.HasPrefix(rest, ":") {
+		return prop, fmt.Errorf("invalid content line %q", line)
+	}
+	prop.Value = rest[1:]
+	return prop, nil
+}
+
+// ICalUnescape reverses ICalEscape
+func ICalUnescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// ParseICalTime reads a DATE or DATE-TIME property. UTC values keep their
+// zone, TZID values use that zone when it is a known IANA name, and floating
+// times and dates are placed in loc. allDay reports a DATE value.
+func ParseICalTime(prop *ICalProperty, loc *time.Location) (t time.Time, allDay bool, err error) {
+	value := strings.TrimSpace(prop.Value)
+	if prop.Params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err = time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	if tzid := prop.Params["TZID"]; tzid != "" {
+		if zone, zoneErr := time.LoadLocation(strings.TrimPrefix(tzid, "/")); zoneErr == nil {
+			loc = zone
+		}
+	}
+	t, err = time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParseICal(t *testing.T) {
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VTODO",
+		"UID:task-1",
+		`SUMMARY:Clean\, then mop\; the floor`,
+		`DESCRIPTION:First line\nsecond line that is folded `,
+		" across two lines",
+		"ATTENDEE;CN=\"Lee, Sam\";ROLE=REQ-PARTICIPANT:mailto:sam@example.com",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"END:VALARM",
+		"END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	root, err := ParseICal(calendar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root.Name != "VCALENDAR" || len(root.Components) != 1 {
+		t.Fatalf("root = %s with %d components", root.Name, len(root.Components))
+	}
+	todo := root.Components[0]
+	if todo.Name != "VTODO" || len(todo.Components) != 1 || todo.Components[0].Name != "VALARM" {
+		t.Fatalf("todo = %+v", todo)
+	}
+	if got := todo.Property("SUMMARY").Text(); got != "Clean, then mop; the floor" {
+		t.Errorf("SUMMARY = %q", got)
+	}
+	if got := todo.Property("DESCRIPTION").Text(); got != "First line\nsecond line that is folded across two lines" {
+		t.Errorf("DESCRIPTION = %q", got)
+	}
+	attendee := todo.Property("ATTENDEE")
+	if attendee.Params["CN"] != "Lee, Sam" || attendee.Params["ROLE"] != "REQ-PARTICIPANT" || attendee.Value != "mailto:sam@example.com" {
+		t.Errorf("ATTENDEE = %+v", attendee)
+	}
+	if todo.Property("DUE") != nil {
+		t.Error("Property returned a property that is not there")
+	}
+}
+
+func TestParseICalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "not an iCalendar object"},
+		{"wrong root", "BEGIN:VTODO\nEND:VTODO", "not an iCalendar object"},
+		{"missing END", "BEGIN:VCALENDAR\nBEGIN:VTODO\nEND:VCALENDAR", "unexpected END:VCALENDAR"},
+		{"unclosed", "BEGIN:VCALENDAR\nBEGIN:VTODO\nEND:VTODO", "missing END:VCALENDAR"},
+		{"two roots", "BEGIN:VCALENDAR\nEND:VCALENDAR\nBEGIN:VCALENDAR\nEND:VCALENDAR", "more than one"},
+		{"property outside", "SUMMARY:x\nBEGIN:VCALENDAR\nEND:VCALENDAR", "outside a component"},
+		{"no colon", "BEGIN:VCALENDAR\nSUMMARY\nEND:VCALENDAR", "invalid content line"},
+		{"no name", "BEGIN:VCALENDAR\n:value\nEND:VCALENDAR", "invalid content line"},
+		{"bad parameter", "BEGIN:VCALENDAR\nDUE;TZID:20261014\nEND:VCALENDAR", "invalid parameter"},
+		{"unterminated quote", "BEGIN:VCALENDAR\nATTENDEE;CN=\"Sam:mailto:x\nEND:VCALENDAR", "unterminated quote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseICal(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestICalEscapeRoundTrip(t *testing.T) {
+	for _, text := range []string{"plain", "a, b; c", `back\slash`, "two\nlines", `trailing\`} {
+		if got := ICalUnescape(ICalEscape(text)); got != text {
+			t.Errorf("round trip of %q = %q", text, got)
+		}
+	}
+}
+
+func TestParseICalTime(t *testing.T) {
+	household := time.FixedZone("household", -5*60*60)
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		prop   ICalProperty
+		want   time.Time
+		allDay bool
+	}{
+		{"date", ICalProperty{Value: "20261014"}, time.Date(2026, 10, 14, 0, 0, 0, 0, household), true},
+		{"explicit date", ICalProperty{Params: map[string]string{"VALUE": "DATE"}, Value: "20261014"}, time.Date(2026, 10, 14, 0, 0, 0, 0, household), true},
+		{"utc", ICalProperty{Value: "20261014T090000Z"}, time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), false},
+		{"floating", ICalProperty{Value: "20261014T090000"}, time.Date(2026, 10, 14, 9, 0, 0, 0, household), false},
+		{"tzid", ICalProperty{Params: map[string]string{"TZID": "Europe/Berlin"}, Value: "20261014T090000"}, time.Date(2026, 10, 14, 9, 0, 0, 0, berlin), false},
+		{"tzid with slash", ICalProperty{Params: map[string]string{"TZID": "/Europe/Berlin"}, Value: "20261014T090000"}, time.Date(2026, 10, 14, 9, 0, 0, 0, berlin), false},
+		// Unknown zones fall back to the household's
+		{"unknown tzid", ICalProperty{Params: map[string]string{"TZID": "Custom Zone 1"}, Value: "20261014T090000"}, time.Date(2026, 10, 14, 9, 0, 0, 0, household), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, allDay, err := ParseICalTime(&tt.prop, household)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) || allDay != tt.allDay {
+				t.Errorf("got %s (allDay %v), want %s (allDay %v)", got, allDay, tt.want, tt.allDay)
+			}
+		})
+	}
+
+	if _, _, err := ParseICalTime(&ICalProperty{Value: "2026-10-14 09:00"}, household); err == nil {
+		t.Error("accepted a malformed time")
+	}
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Registers GIF decoding
+	"image/jpeg"
+	_ "image/png" // Registers PNG decoding
+)
+
+// MaxImagePixels caps the size of images that are decoded, so a small file
+// cannot unpack into gigabytes of pixels
+const MaxImagePixels = 40_000_000
+
+// ErrImageTooLarge is returned for images with more than MaxImagePixels
+var ErrImageTooLarge = errors.New("image has too many pixels")
+
+// DecodeImage decodes a JPEG, PNG or GIF image after checking its dimensions
+func DecodeImage(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > MaxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// FitWithin scales an image down, keeping its aspect ratio, so that neither
+// side is longer than size. Smaller images are returned unchanged.
+func FitWithin(img image.Image, size int) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w <= size && h <= size {
+		return img
+	}
+	if w >= h {
+		return Resize(img, size, max(1, h*size/w))
+	}
+	return Resize(img, max(1, w*size/h), size)
+}
+
+// CropSquare cuts the largest centred square out of an image
+func CropSquare(img image.Image) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), img, image.Pt(x, y), draw.Src)
+	return square
+}
+
+// Resize scales an image to exactly w by h pixels. Each output pixel is the
+// average of the source pixels it covers, which keeps downscaled photos
+// smooth.
+func Resize(img image.Image, w, h int) image.Image {
+	src := image.NewRGBA(img.Bounds())
+	draw.Draw(src, src.Bounds(), img, img.Bounds().Min, draw.Src)
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += int(p[0])
+					g += int(p[1])
+					b += int(p[2])
+					a += int(p[3])
+					n++
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// EncodeJPEG encodes an image as a JPEG, laying transparent areas over white
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
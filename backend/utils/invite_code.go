@@ -1,35 +1,109 @@
 package utils
 
 import (
-	"math/rand"
+	"crypto/rand"
 	"strings"
-	"time"
 )
 
 const (
-	letterBytes   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	letterIdxBits = 6                    // 6 bits to represent a letter index
-	letterIdxMask = 1<<letterIdxBits - 1 // All 1-bits, as many as letterIdxBits
-	letterIdxMax  = 63 / letterIdxBits   // # of letter indices fitting in 63 bits
-)
+	// inviteAlphabet leaves out characters that are easy to confuse when read
+	// aloud or copied by hand (0/O, 1/I/L). Its length is prime, which lets the
+	// position-weighted checksum catch every single-character typo and every
+	// swap of two neighbouring characters.
+	inviteAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+	// InviteCodeLength is the canonical length of an invite code, including
+	// its trailing checksum character.
+	InviteCodeLength = 8
 
-var src = rand.NewSource(time.Now().UnixNano())
+	// inviteGroupSize is the number of characters between hyphens in the
+	// display form of a code.
+	inviteGroupSize = 4
+)
 
-// GenerateInviteCode generates a random invite code with the specified length
+// GenerateInviteCode generates a random invite code with the specified length.
+// The last character is a checksum over the others, so a code that is not
+// found can be reported as mistyped with ValidInviteCode.
 func GenerateInviteCode(length int) string {
-	sb := strings.Builder{}
-	sb.Grow(length)
-	// A src.Int63() generates 63 random bits, enough for letterIdxMax characters!
-	for i, cache, remain := length-1, src.Int63(), letterIdxMax; i >= 0; {
-		if remain == 0 {
-			cache, remain = src.Int63(), letterIdxMax
+	if length < 2 {
+		length = 2
+	}
+
+	body := make([]byte, length-1)
+	buf := make([]byte, 1)
+	n := byte(len(inviteAlphabet))
+	// Largest multiple of n that fits in a byte; values above it are rejected
+	// so every character is equally likely.
+	limit := 256 - 256%int(n)
+	for i := 0; i < len(body); {
+		if _, err := rand.Read(buf); err != nil {
+			// crypto/rand only fails when the OS has no entropy source, and
+			// a predictable code would be worse than no code at all
+			panic("invite code: reading random bytes: " + err.Error())
+		}
+		if int(buf[0]) >= limit {
+			continue
+		}
+		body[i] = inviteAlphabet[buf[0]%n]
+		i++
+	}
+
+	return string(body) + string(inviteChecksum(string(body)))
+}
+
+// NormalizeInviteCode turns user input into the canonical stored form by
+// upper-casing it and dropping spaces and hyphens.
+func NormalizeInviteCode(input string) string {
+	var sb strings.Builder
+	sb.Grow(len(input))
+	for _, r := range strings.ToUpper(input) {
+		switch r {
+		case ' ', '-', '\t':
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// ValidInviteCode reports whether a normalized code uses the invite alphabet
+// and carries a correct checksum character.
+func ValidInviteCode(code string) bool {
+	if len(code) < 2 {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if strings.IndexByte(inviteAlphabet, code[i]) < 0 {
+			return false
 		}
-		if idx := int(cache & letterIdxMask); idx < len(letterBytes) {
-			sb.WriteByte(letterBytes[idx])
-			i--
+	}
+	body := code[:len(code)-1]
+	return inviteChecksum(body) == code[len(code)-1]
+}
+
+// FormatInviteCode splits a code into hyphenated groups for display,
+// e.g. "ABCDEFGH" becomes "ABCD-EFGH".
+func FormatInviteCode(code string) string {
+	if len(code) <= inviteGroupSize {
+		return code
+	}
+	var sb strings.Builder
+	for i := 0; i < len(code); i++ {
+		if i > 0 && i%inviteGroupSize == 0 {
+			sb.WriteByte('-')
 		}
-		cache >>= letterIdxBits
-		remain--
+		sb.WriteByte(code[i])
 	}
 	return sb.String()
 }
+
+// inviteChecksum computes the check character for body as the sum of each
+// character's alphabet index weighted by its position, modulo the alphabet size.
+func inviteChecksum(body string) byte {
+	n := len(inviteAlphabet)
+	sum := 0
+	for i := 0; i < len(body); i++ {
+		sum += (i + 1) * strings.IndexByte(inviteAlphabet, body[i])
+	}
+	return inviteAlphabet[sum%n]
+}
@@ -1,8 +1,16 @@
 package utils
 
 import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -15,40 +23,245 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
-var jwtSecret []byte
+const (
+	// AccessTokenTTL is how long an access token is accepted
+	AccessTokenTTL = 15 * time.Minute
+
+	// RefreshTokenTTL is how long an unused refresh token stays redeemable
+	RefreshTokenTTL = 30 * 24 * time.Hour
+
+	// defaultSecret is only acceptable for local development
+	defaultSecret = "your-super-secret-jwt-key-change-this-in-production"
+
+	// defaultKeyID is the kid given to the key built from JWT_SECRET
+	defaultKeyID = "default"
+)
+
+// jwtKey is one entry of the keyset. Keys without a private half (or secret)
+// can only verify tokens, which is how retired keys are kept around until
+// every token they signed has expired.
+type jwtKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+type jwtKeySet struct {
+	active *jwtKey
+	keys   map[string]*jwtKey
+}
+
+var keys *jwtKeySet
+
+// keySetFile is the JSON format of JWT_KEYS_FILE and JWT_KEYS
+type keySetFile struct {
+	ActiveKid string `json:"activeKid"`
+	Keys      []struct {
+		Kid            string `json:"kid"`
+		Alg            string `json:"alg"`
+		Secret         string `json:"secret"`
+		PrivateKey     string `json:"privateKey"`
+		PrivateKeyFile string `json:"privateKeyFile"`
+		PublicKey      string `json:"publicKey"`
+		PublicKeyFile  string `json:"publicKeyFile"`
+	} `json:"keys"`
+}
+
+// LoadKeys configures the keys used to sign and verify tokens. A keyset is
+// read from the file named by JWT_KEYS_FILE or the JSON in JWT_KEYS; without
+// either, JWT_SECRET is used as a single HS256 key. In production
+// (APP_ENV=production) falling back to the built-in development secret is an
+// error.
+func LoadKeys() error {
+	var data []byte
+	baseDir := "."
+	if path := os.Getenv("JWT_KEYS_FILE"); path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return fmt.Errorf("reading JWT keyset: %w", err)
+		}
+		baseDir = filepath.Dir(path)
+	} else if inline := os.Getenv("JWT_KEYS"); inline != "" {
+		data = []byte(inline)
+	}
+
+	if data != nil {
+		set, err := parseKeySet(data, baseDir)
+		if err != nil {
+			return err
+		}
+		keys = set
+		return nil
+	}
 
-func init() {
 	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
+	if secret == "" || secret == defaultSecret {
+		if os.Getenv("APP_ENV") == "production" {
+			return errors.New("JWT_SECRET, JWT_KEYS or JWT_KEYS_FILE must be set in production")
+		}
 		// Use a default secret for development - in production this should be set via environment
-		secret = "your-super-secret-jwt-key-change-this-in-production"
+		secret = defaultSecret
 	}
-	jwtSecret = []byte(secret)
+
+	key := &jwtKey{
+		id:        defaultKeyID,
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+	keys = &jwtKeySet{active: key, keys: map[string]*jwtKey{key.id: key}}
+	return nil
 }
 
-// GenerateJWT creates a new JWT token for a user
-func GenerateJWT(userID, householdID, deviceID string) (string, error) {
+func parseKeySet(data []byte, baseDir string) (*jwtKeySet, error) {
+	var file keySetFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parsing JWT keyset: %w", err)
+	}
+
+	set := &jwtKeySet{keys: make(map[string]*jwtKey)}
+	for _, k := range file.Keys {
+		if k.Kid == "" {
+			return nil, errors.New("JWT keyset: every key needs a kid")
+		}
+		if _, dup := set.keys[k.Kid]; dup {
+			return nil, fmt.Errorf("JWT keyset: duplicate kid %q", k.Kid)
+		}
+
+		privatePEM, err := readKeyMaterial(k.PrivateKey, k.PrivateKeyFile, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", k.Kid, err)
+		}
+		publicPEM, err := readKeyMaterial(k.PublicKey, k.PublicKeyFile, baseDir)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: %w", k.Kid, err)
+		}
+
+		key := &jwtKey{id: k.Kid}
+		switch k.Alg {
+		case "HS256":
+			if k.Secret == "" || k.Secret == defaultSecret {
+				return nil, fmt.Errorf("JWT key %q: HS256 keys need a non-default secret", k.Kid)
+			}
+			key.method = jwt.SigningMethodHS256
+			key.signKey = []byte(k.Secret)
+			key.verifyKey = []byte(k.Secret)
+		case "EdDSA":
+			key.method = jwt.SigningMethodEdDSA
+			if privatePEM != "" {
+				private, err := jwt.ParseEdPrivateKeyFromPEM([]byte(privatePEM))
+				if err != nil {
+					return nil, fmt.Errorf("JWT key %q: %w", k.Kid, err)
+				}
+				key.signKey = private
+				key.verifyKey = private.(ed25519.PrivateKey).Public()
+			} else if publicPEM != "" {
+				if key.verifyKey, err = jwt.ParseEdPublicKeyFromPEM([]byte(publicPEM)); err != nil {
+					return nil, fmt.Errorf("JWT key %q: %w", k.Kid, err)
+				}
+			}
+		case "ES256":
+			key.method = jwt.SigningMethodES256
+			if privatePEM != "" {
+				private, err := jwt.ParseECPrivateKeyFromPEM([]byte(privatePEM))
+				if err != nil {
+					return nil, fmt.Errorf("JWT key %q: %w", k.Kid, err)
+				}
+				key.signKey = private
+				key.verifyKey = &private.PublicKey
+			} else if publicPEM != "" {
+				if key.verifyKey, err = jwt.ParseECPublicKeyFromPEM([]byte(publicPEM)); err != nil {
+					return nil, fmt.Errorf("JWT key %q: %w", k.Kid, err)
+				}
+			}
+			if pub, ok := key.verifyKey.(*ecdsa.PublicKey); ok && pub.Curve != elliptic.P256() {
+				return nil, fmt.Errorf("JWT key %q: ES256 requires a P-256 key", k.Kid)
+			}
+		default:
+			return nil, fmt.Errorf("JWT key %q: unsupported alg %q", k.Kid, k.Alg)
+		}
+
+		if key.verifyKey == nil {
+			return nil, fmt.Errorf("JWT key %q: no key material", k.Kid)
+		}
+		set.keys[k.Kid] = key
+	}
+
+	active, ok := set.keys[file.ActiveKid]
+	if !ok {
+		return nil, fmt.Errorf("JWT keyset: active kid %q not found", file.ActiveKid)
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("JWT keyset: active key %q has no private key", file.ActiveKid)
+	}
+	set.active = active
+	return set, nil
+}
+
+func readKeyMaterial(inline, path, baseDir string) (string, error) {
+	if inline != "" || path == "" {
+		return inline, nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// currentKeys returns the loaded keyset, loading it from the environment on
+// first use so packages that never call LoadKeys still work in development
+func currentKeys() *jwtKeySet {
+	if keys == nil {
+		if err := LoadKeys(); err != nil {
+			panic(err)
+		}
+	}
+	return keys
+}
+
+// GenerateJWT creates a new short-lived access token for a user. The session
+// ID is stored in the jti claim so the token can be revoked server-side.
+func GenerateJWT(userID, householdID, deviceID, sessionID string) (string, error) {
 	claims := JWTClaims{
 		UserID:      userID,
 		HouseholdID: householdID,
 		DeviceID:    deviceID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(365 * 24 * time.Hour)), // 1 year
+			ID:        sessionID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	active := currentKeys().active
+	token := jwt.NewWithClaims(active.method, claims)
+	token.Header["kid"] = active.id
+	return token.SignedString(active.signKey)
 }
 
-// ValidateJWT validates a JWT token and returns the claims
+// ValidateJWT validates a JWT token against the key named by its kid header
+// and returns the claims
 func ValidateJWT(tokenString string) (*JWTClaims, error) {
+	set := currentKeys()
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			// Tokens signed before key IDs were introduced
+			kid = defaultKeyID
+		}
+		key, ok := set.keys[kid]
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		return jwtSecret, nil
+		return key.verifyKey, nil
 	})
 
 	if err != nil {
@@ -61,3 +274,47 @@ func ValidateJWT(tokenString string) (*JWTClaims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// JWKS returns the public halves of the asymmetric keys in JSON Web Key Set
+// form. HMAC secrets are never published.
+func JWKS() map[string]interface{} {
+	set := currentKeys()
+	ids := make([]string, 0, len(set.keys))
+	for id := range set.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := make([]map[string]string, 0, len(ids))
+	for _, id := range ids {
+		key := set.keys[id]
+		switch pub := key.verifyKey.(type) {
+		case ed25519.PublicKey:
+			jwks = append(jwks, map[string]string{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"x":   base64.RawURLEncoding.EncodeToString(pub),
+				"kid": key.id,
+				"alg": key.method.Alg(),
+				"use": "sig",
+			})
+		case *ecdsa.PublicKey:
+			ecdh, err := pub.ECDH()
+			if err != nil {
+				continue
+			}
+			// Uncompressed point: 0x04 || X || Y, each 32 bytes for P-256
+			point := ecdh.Bytes()
+			jwks = append(jwks, map[string]string{
+				"kty": "EC",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(point[1:33]),
+				"y":   base64.RawURLEncoding.EncodeToString(point[33:]),
+				"kid": key.id,
+				"alg": key.method.Alg(),
+				"use": "sig",
+			})
+		}
+	}
+	return map[string]interface{}{"keys": jwks}
+}
@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func pemBlock(t *testing.T, kind string, der []byte, err error) string {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}))
+}
+
+func newEd25519PEM(t *testing.T) (private, public string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	private = pemBlock(t, "PRIVATE KEY", der, err)
+	der, err = x509.MarshalPKIXPublicKey(pub)
+	public = pemBlock(t, "PUBLIC KEY", der, err)
+	return private, public
+}
+
+// useKeySet loads the keyset for the rest of the test
+func useKeySet(t *testing.T, set interface{}) error {
+	t.Helper()
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("JWT_KEYS_FILE", "")
+	t.Setenv("JWT_KEYS", string(data))
+	return LoadKeys()
+}
+
+type testKey = map[string]string
+
+func keySet(active string, keys ...testKey) map[string]interface{} {
+	return map[string]interface{}{"activeKid": active, "keys": keys}
+}
+
+func tokenKid(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestJWTKeyRotation(t *testing.T) {
+	oldPrivate, oldPublic := newEd25519PEM(t)
+	newPrivate, _ := newEd25519PEM(t)
+
+	if err := useKeySet(t, keySet("2026-04", testKey{"kid": "2026-04", "alg": "EdDSA", "privateKey": oldPrivate})); err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := GenerateJWT("user-1", "house-1", "device-1", "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKid(t, oldToken); kid != "2026-04" {
+		t.Fatalf("kid = %q, want 2026-04", kid)
+	}
+
+	// Rotate: the new key signs, the old one only verifies
+	if err := useKeySet(t, keySet("2026-10",
+		testKey{"kid": "2026-10", "alg": "EdDSA", "privateKey": newPrivate},
+		testKey{"kid": "2026-04", "alg": "EdDSA", "publicKey": oldPublic},
+	)); err != nil {
+		t.Fatal(err)
+	}
+	claims, err := ValidateJWT(oldToken)
+	if err != nil {
+		t.Fatalf("token from the retired key: %v", err)
+	}
+	if claims.UserID != "user-1" || claims.HouseholdID != "house-1" || claims.DeviceID != "device-1" || claims.ID != "session-1" {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	newToken, err := GenerateJWT("user-1", "house-1", "device-1", "session-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kid := tokenKid(t, newToken); kid != "2026-10" {
+		t.Fatalf("kid = %q, want 2026-10", kid)
+	}
+	if _, err := ValidateJWT(newToken); err != nil {
+		t.Fatalf("token from the active key: %v", err)
+	}
+
+	// Once the old key is dropped its tokens stop working
+	if err := useKeySet(t, keySet("2026-10", testKey{"kid": "2026-10", "alg": "EdDSA", "privateKey": newPrivate})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateJWT(oldToken); err == nil {
+		t.Fatal("token from a removed key was accepted")
+	}
+}
+
+func TestValidateJWTRejectsForgedTokens(t *testing.T) {
+	edPrivate, edPublic := newEd25519PEM(t)
+	if err := useKeySet(t, keySet("ed",
+		testKey{"kid": "ed", "alg": "EdDSA", "privateKey": edPrivate},
+		testKey{"kid": "hs", "alg": "HS256", "secret": "shared-secret"},
+	)); err != nil {
+		t.Fatal(err)
+	}
+
+	claims := JWTClaims{
+		UserID:      "user-1",
+		HouseholdID: "house-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "session-1",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	_, otherEd, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"HS256 key by kid", sign(jwt.SigningMethodHS256, "hs", []byte("shared-secret")), true},
+		{"unknown kid", sign(jwt.SigningMethodHS256, "nope", []byte("shared-secret")), false},
+		{"wrong secret", sign(jwt.SigningMethodHS256, "hs", []byte("guessed")), false},
+		// Without a kid the key built from JWT_SECRET is assumed, which this
+		// keyset does not have
+		{"no kid", sign(jwt.SigningMethodHS256, "", []byte("shared-secret")), false},
+		// HMAC keyed with the public key must not pass as the EdDSA key
+		{"algorithm confusion", sign(jwt.SigningMethodHS256, "ed", []byte(edPublic)), false},
+		{"other EdDSA key", sign(jwt.SigningMethodEdDSA, "ed", otherEd), false},
+		{"none algorithm", sign(jwt.SigningMethodNone, "ed", jwt.UnsafeAllowNoneSignatureType), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateJWT(tt.token)
+			if (err == nil) != tt.valid {
+				t.Errorf("ValidateJWT error = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestLoadKeysRejectsBadKeySets(t *testing.T) {
+	edPrivate, edPublic := newEd25519PEM(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	p384 := pemBlock(t, "EC PRIVATE KEY", der, err)
+
+	tests := []struct {
+		name string
+		set  interface{}
+		want string
+	}{
+		{"missing kid", keySet("a", testKey{"alg": "EdDSA", "privateKey": edPrivate}), "needs a kid"},
+		{"duplicate kid", keySet("a",
+			testKey{"kid": "a", "alg": "EdDSA", "privateKey": edPrivate},
+			testKey{"kid": "a", "alg": "EdDSA", "publicKey": edPublic},
+		), "duplicate kid"},
+		{"unknown active kid", keySet("b", testKey{"kid": "a", "alg": "EdDSA", "privateKey": edPrivate}), "not found"},
+		{"active key without private half", keySet("a", testKey{"kid": "a", "alg": "EdDSA", "publicKey": edPublic}), "no private key"},
+		{"default HS256 secret", keySet("a", testKey{"kid": "a", "alg": "HS256", "secret": defaultSecret}), "non-default secret"},
+		{"unsupported alg", keySet("a", testKey{"kid": "a", "alg": "RS256", "privateKey": edPrivate}), "unsupported alg"},
+		{"ES256 on another curve", keySet("a", testKey{"kid": "a", "alg": "ES256", "privateKey": p384}), "P-256"},
+		{"no key material", keySet("a", testKey{"kid": "a", "alg": "EdDSA"}), "no key material"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := useKeySet(t, tt.set)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadKeys error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadKeysRefusesDevelopmentSecretInProduction(t *testing.T) {
+	t.Setenv("JWT_KEYS", "")
+	t.Setenv("JWT_KEYS_FILE", "")
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("APP_ENV", "production")
+	if err := LoadKeys(); err == nil {
+		t.Fatal("LoadKeys accepted the development secret in production")
+	}
+
+	t.Setenv("JWT_SECRET", "a-real-secret")
+	if err := LoadKeys(); err != nil {
+		t.Fatalf("LoadKeys: %v", err)
+	}
+}
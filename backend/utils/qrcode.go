@@ -0,0 +1,571 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// QRErrorCorrection is the error correction level of a QR code
+type QRErrorCorrection int
+
+const (
+	QRLow QRErrorCorrection = iota
+	QRMedium
+	QRQuartile
+	QRHigh
+)
+
+// formatBits returns the two bits that identify the level in the format info
+func (e QRErrorCorrection) formatBits() int {
+	return [...]int{1, 0, 3, 2}[e]
+}
+
+// ParseQRErrorCorrection parses the single-letter level names L, M, Q and H
+func ParseQRErrorCorrection(s string) (QRErrorCorrection, bool) {
+	switch strings.ToUpper(s) {
+	case "L":
+		return QRLow, true
+	case "M":
+		return QRMedium, true
+	case "Q":
+		return QRQuartile, true
+	case "H":
+		return QRHigh, true
+	}
+	return QRMedium, false
+}
+
+// Tables from ISO/IEC 18004, indexed by [level][version]; index 0 is unused.
+var qrEccCodewordsPerBlock = [4][41]int{
+	{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+	{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+}
+
+var qrNumErrorCorrectionBlocks = [4][41]int{
+	{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+	{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+	{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+	{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+}
+
+// ErrQRDataTooLong is returned when the payload does not fit in a version 40 code
+var ErrQRDataTooLong = errors.New("data too long for a QR code")
+
+// QRCode is an encoded QR symbol. Modules are addressed as (x, y) with the
+// origin in the top-left corner; true means a dark module.
+type QRCode struct {
+	Size       int
+	version    int
+	ecl        QRErrorCorrection
+	mask       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+// EncodeQR encodes data in byte mode using the smallest version that fits at
+// the requested error correction level.
+func EncodeQR(data []byte, ecl QRErrorCorrection) (*QRCode, error) {
+	version := 0
+	for v := 1; v <= 40; v++ {
+		countBits := 8
+		if v >= 10 {
+			countBits = 16
+		}
+		if len(data) >= 1<<countBits {
+			continue
+		}
+		if 4+countBits+len(data)*8 <= qrNumDataCodewords(v, ecl)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrQRDataTooLong
+	}
+
+	// Mode indicator, character count and payload
+	var bb qrBitBuffer
+	bb.append(0x4, 4)
+	if version >= 10 {
+		bb.append(len(data), 16)
+	} else {
+		bb.append(len(data), 8)
+	}
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	// Terminator, byte alignment and alternating pad bytes
+	capacity := qrNumDataCodewords(version, ecl) * 8
+	terminator := capacity - len(bb)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bb.append(0, terminator)
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := 0xEC; len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - uint(i&7))
+		}
+	}
+
+	q := &QRCode{Size: version*4 + 17, version: version, ecl: ecl}
+	q.modules = make([][]bool, q.Size)
+	q.isFunction = make([][]bool, q.Size)
+	for i := range q.modules {
+		q.modules[i] = make([]bool, q.Size)
+		q.isFunction[i] = make([]bool, q.Size)
+	}
+
+	q.drawFunctionPatterns()
+	q.drawCodewords(q.addEccAndInterleave(codewords))
+
+	// Pick the mask with the lowest penalty score
+	bestMask, minPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penaltyScore(); minPenalty < 0 || penalty < minPenalty {
+			bestMask, minPenalty = mask, penalty
+		}
+		q.applyMask(mask) // XOR undoes the mask
+	}
+	q.applyMask(bestMask)
+	q.drawFormatBits(bestMask)
+	q.mask = bestMask
+	q.isFunction = nil
+
+	return q, nil
+}
+
+// Module reports whether the module at (x, y) is dark; out of range is light
+func (q *QRCode) Module(x, y int) bool {
+	return x >= 0 && x < q.Size && y >= 0 && y < q.Size && q.modules[y][x]
+}
+
+// PNG renders the code as a PNG roughly size pixels wide, surrounded by a
+// quiet zone of border modules. Modules are never smaller than one pixel.
+func (q *QRCode) PNG(size, border int) ([]byte, error) {
+	total := q.Size + border*2
+	scale := size / total
+	if scale < 1 {
+		scale = 1
+	}
+
+	palette := color.Palette{color.White, color.Black}
+	img := image.NewPaletted(image.Rect(0, 0, total*scale, total*scale), palette)
+	for y := 0; y < total*scale; y++ {
+		for x := 0; x < total*scale; x++ {
+			if q.Module(x/scale-border, y/scale-border) {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the code as a scalable SVG document size pixels wide
+func (q *QRCode) SVG(size, border int) string {
+	total := q.Size + border*2
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n", size, size, total, total)
+	sb.WriteString(`<rect width="100%" height="100%" fill="#FFFFFF"/>` + "\n")
+	sb.WriteString(`<path d="`)
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.modules[y][x] {
+				fmt.Fprintf(&sb, "M%d,%dh1v1h-1z", x+border, y+border)
+			}
+		}
+	}
+	sb.WriteString(`" fill="#000000"/>` + "\n")
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
+
+func (q *QRCode) setFunctionModule(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+func (q *QRCode) drawFunctionPatterns() {
+	// Timing patterns
+	for i := 0; i < q.Size; i++ {
+		q.setFunctionModule(6, i, i%2 == 0)
+		q.setFunctionModule(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with their separators
+	q.drawFinderPattern(3, 3)
+	q.drawFinderPattern(q.Size-4, 3)
+	q.drawFinderPattern(3, q.Size-4)
+
+	// Alignment patterns, skipping the three finder corners
+	positions := q.alignmentPatternPositions()
+	n := len(positions)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if (i == 0 && j == 0) || (i == 0 && j == n-1) || (i == n-1 && j == 0) {
+				continue
+			}
+			q.drawAlignmentPattern(positions[i], positions[j])
+		}
+	}
+
+	// Reserve the format areas; real bits are drawn after masking
+	q.drawFormatBits(0)
+	q.drawVersion()
+}
+
+func (q *QRCode) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			dist := maxInt(absInt(dx), absInt(dy))
+			xx, yy := x+dx, y+dy
+			if xx >= 0 && xx < q.Size && yy >= 0 && yy < q.Size {
+				q.setFunctionModule(xx, yy, dist != 2 && dist != 4)
+			}
+		}
+	}
+}
+
+func (q *QRCode) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunctionModule(x+dx, y+dy, maxInt(absInt(dx), absInt(dy)) != 1)
+		}
+	}
+}
+
+func (q *QRCode) alignmentPatternPositions() []int {
+	if q.version == 1 {
+		return nil
+	}
+	numAlign := q.version/7 + 2
+	step := (q.version*8 + numAlign*3 + 5) / (numAlign*4 - 4) * 2
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, q.Size-7; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+	return result
+}
+
+func (q *QRCode) drawFormatBits(mask int) {
+	data := q.ecl.formatBits()<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	// First copy, around the top-left finder
+	for i := 0; i <= 5; i++ {
+		q.setFunctionModule(8, i, getBit(bits, i))
+	}
+	q.setFunctionModule(8, 7, getBit(bits, 6))
+	q.setFunctionModule(8, 8, getBit(bits, 7))
+	q.setFunctionModule(7, 8, getBit(bits, 8))
+	for i := 9; i < 15; i++ {
+		q.setFunctionModule(14-i, 8, getBit(bits, i))
+	}
+
+	// Second copy, split between the other two finders
+	for i := 0; i < 8; i++ {
+		q.setFunctionModule(q.Size-1-i, 8, getBit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunctionModule(8, q.Size-15+i, getBit(bits, i))
+	}
+	q.setFunctionModule(8, q.Size-8, true) // Always dark
+}
+
+func (q *QRCode) drawVersion() {
+	if q.version < 7 {
+		return
+	}
+	rem := q.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := q.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		bit := getBit(bits, i)
+		a := q.Size - 11 + i%3
+		b := i / 3
+		q.setFunctionModule(a, b, bit)
+		q.setFunctionModule(b, a, bit)
+	}
+}
+
+// addEccAndInterleave splits data into blocks, appends Reed-Solomon
+// codewords to each and interleaves the result.
+func (q *QRCode) addEccAndInterleave(data []byte) []byte {
+	numBlocks := qrNumErrorCorrectionBlocks[q.ecl][q.version]
+	blockEccLen := qrEccCodewordsPerBlock[q.ecl][q.version]
+	rawCodewords := qrNumRawDataModules(q.version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(blockEccLen)
+	blocks := make([][]byte, 0, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		datLen := shortBlockLen - blockEccLen
+		if i >= numShortBlocks {
+			datLen++
+		}
+		dat := data[k : k+datLen]
+		k += datLen
+		block := make([]byte, 0, shortBlockLen+1)
+		block = append(block, dat...)
+		if i < numShortBlocks {
+			block = append(block, 0) // Padding, skipped when interleaving
+		}
+		block = append(block, reedSolomonRemainder(dat, divisor)...)
+		blocks = append(blocks, block)
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortBlockLen-blockEccLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// drawCodewords places data in the zigzag pattern, skipping function modules
+func (q *QRCode) drawCodewords(data []byte) {
+	i := 0
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				upward := (right+1)&2 == 0
+				y := vert
+				if upward {
+					y = q.Size - 1 - vert
+				}
+				if !q.isFunction[y][x] && i < len(data)*8 {
+					q.modules[y][x] = getBit(int(data[i>>3]), 7-(i&7))
+					i++
+				}
+			}
+		}
+	}
+}
+
+func (q *QRCode) applyMask(mask int) {
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !q.isFunction[y][x] {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penaltyScore implements the four mask evaluation rules of the standard
+func (q *QRCode) penaltyScore() int {
+	penalty := 0
+	finderLike := [][]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+
+	line := make([]bool, q.Size)
+	for _, vertical := range []bool{false, true} {
+		for a := 0; a < q.Size; a++ {
+			for b := 0; b < q.Size; b++ {
+				if vertical {
+					line[b] = q.modules[b][a]
+				} else {
+					line[b] = q.modules[a][b]
+				}
+			}
+
+			// Rule 1: runs of five or more same-coloured modules
+			run := 1
+			for b := 1; b <= q.Size; b++ {
+				if b < q.Size && line[b] == line[b-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += 3 + run - 5
+				}
+				run = 1
+			}
+
+			// Rule 3: patterns resembling a finder
+			for b := 0; b+11 <= q.Size; b++ {
+				for _, pattern := range finderLike {
+					match := true
+					for k, dark := range pattern {
+						if line[b+k] != dark {
+							match = false
+							break
+						}
+					}
+					if match {
+						penalty += 40
+					}
+				}
+			}
+		}
+	}
+
+	// Rule 2: 2x2 blocks of the same colour
+	for y := 0; y < q.Size-1; y++ {
+		for x := 0; x < q.Size-1; x++ {
+			c := q.modules[y][x]
+			if c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+				penalty += 3
+			}
+		}
+	}
+
+	// Rule 4: balance of dark and light modules
+	dark := 0
+	for y := 0; y < q.Size; y++ {
+		for x := 0; x < q.Size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+		}
+	}
+	total := q.Size * q.Size
+	k := (absInt(dark*20-total*10)+total-1)/total - 1
+	if k > 0 {
+		penalty += k * 10
+	}
+
+	return penalty
+}
+
+func qrNumRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		result -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func qrNumDataCodewords(version int, ecl QRErrorCorrection) int {
+	return qrNumRawDataModules(version)/8 -
+		qrEccCodewordsPerBlock[ecl][version]*qrNumErrorCorrectionBlocks[ecl][version]
+}
+
+// reedSolomonDivisor returns the generator polynomial of the given degree,
+// highest coefficient first with the leading 1 omitted.
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = reedSolomonMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = reedSolomonMultiply(root, 0x02)
+	}
+	return result
+}
+
+func reedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= reedSolomonMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+// reedSolomonMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func reedSolomonMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+type qrBitBuffer []bool
+
+func (bb *qrBitBuffer) append(val, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*bb = append(*bb, (val>>uint(i))&1 != 0)
+	}
+}
+
+func getBit(x, i int) bool {
+	return (x>>uint(i))&1 != 0
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The golden matrices in testdata/qr were cross-checked against ZXing: each
+// decodes to its payload at the expected level, and ZXing's encoder produces
+// the identical matrix when forced to the same version and mask. Each file is
+// the payload on the first line followed by one row per line, '#' for dark.
+func TestEncodeQRGolden(t *testing.T) {
+	tests := []struct {
+		file    string
+		ecl     QRErrorCorrection
+		version int
+		mask    int
+	}{
+		{"v1-l", QRLow, 1, 3},
+		{"v2-m", QRMedium, 2, 2},
+		{"v3-q", QRQuartile, 3, 0},
+		{"v4-h", QRHigh, 4, 3},
+		{"v5-q", QRQuartile, 5, 6},
+		{"v7-h", QRHigh, 7, 6},
+		{"v10-m", QRMedium, 10, 2},
+		{"v15-l", QRLow, 15, 2},
+		{"v25-q", QRQuartile, 25, 2},
+		{"v40-l", QRLow, 40, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			golden, err := os.ReadFile(filepath.Join("testdata", "qr", tt.file+".txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(golden), "\n"), "\n")
+			payload, rows := lines[0], lines[1:]
+
+			q, err := EncodeQR([]byte(payload), tt.ecl)
+			if err != nil {
+				t.Fatalf("EncodeQR: %v", err)
+			}
+			if q.version != tt.version {
+				t.Errorf("version = %d, want %d", q.version, tt.version)
+			}
+			if q.mask != tt.mask {
+				t.Errorf("mask = %d, want %d", q.mask, tt.mask)
+			}
+			if q.Size != len(rows) {
+				t.Fatalf("size = %d, want %d", q.Size, len(rows))
+			}
+			for y, row := range rows {
+				if got := qrRow(q, y); got != row {
+					t.Fatalf("row %d differs\n got %s\nwant %s", y, got, row)
+				}
+			}
+		})
+	}
+}
+
+func qrRow(q *QRCode, y int) string {
+	var sb strings.Builder
+	for x := 0; x < q.Size; x++ {
+		if q.Module(x, y) {
+			sb.WriteByte('#')
+		} else {
+			sb.WriteByte('.')
+		}
+	}
+	return sb.String()
+}
+
+func TestEncodeQRCapacity(t *testing.T) {
+	tests := []struct {
+		ecl     QRErrorCorrection
+		length  int
+		version int
+	}{
+		// Largest byte-mode payloads of a few versions, and one byte more
+		{QRLow, 17, 1},
+		{QRLow, 18, 2},
+		{QRHigh, 7, 1},
+		{QRHigh, 8, 2},
+		{QRMedium, 180, 9},
+		{QRMedium, 181, 10},
+		{QRLow, 2953, 40},
+		{QRHigh, 1273, 40},
+	}
+
+	for _, tt := range tests {
+		q, err := EncodeQR(bytes.Repeat([]byte("a"), tt.length), tt.ecl)
+		if err != nil {
+			t.Errorf("EncodeQR(%d bytes, %d): %v", tt.length, tt.ecl, err)
+			continue
+		}
+		if q.version != tt.version {
+			t.Errorf("EncodeQR(%d bytes, %d) version = %d, want %d", tt.length, tt.ecl, q.version, tt.version)
+		}
+	}
+
+	for _, ecl := range []QRErrorCorrection{QRLow, QRHigh} {
+		length := map[QRErrorCorrection]int{QRLow: 2954, QRHigh: 1274}[ecl]
+		if _, err := EncodeQR(bytes.Repeat([]byte("a"), length), ecl); !errors.Is(err, ErrQRDataTooLong) {
+			t.Errorf("EncodeQR(%d bytes, %d) error = %v, want ErrQRDataTooLong", length, ecl, err)
+		}
+	}
+}
+
+func TestParseQRErrorCorrection(t *testing.T) {
+	tests := []struct {
+		in   string
+		want QRErrorCorrection
+		ok   bool
+	}{
+		{"L", QRLow, true},
+		{"m", QRMedium, true},
+		{"Q", QRQuartile, true},
+		{"h", QRHigh, true},
+		{"", QRMedium, false},
+		{"X", QRMedium, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseQRErrorCorrection(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseQRErrorCorrection(%q) = %d, %v; want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rruleSearchYears bounds how far ahead Next looks for an occurrence
+const rruleSearchYears = 8
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// RRuleDay is a BYDAY entry such as MO, or 1MO / -1FR for the first Monday
+// or last Friday of the month
+type RRuleDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// RRule is the subset of RFC 5545 recurrence rules tasks support: FREQ,
+// INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY and BYMONTH
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []RRuleDay
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+// ParseRRule parses a recurrence rule, with or without the "RRULE:" prefix.
+// A date-only UNTIL is read as the end of that day in loc.
+func ParseRRule(value string, loc *time.Location) (*RRule, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+	if value == "" {
+		return nil, errors.New("empty recurrence rule")
+	}
+
+	rule := &RRule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate rule part %s", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			switch val {
+			case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+				rule.Freq = val
+			default:
+				return nil, fmt.Errorf("unsupported FREQ %s", val)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 || n > 366 {
+				return nil, errors.New("INTERVAL must be between 1 and 366")
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return nil, errors.New("COUNT must be positive")
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseRRuleUntil(val, loc)
+			if err != nil {
+				return nil, err
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(val, ",") {
+				weekday, ok := rruleWeekdays[day[max(len(day)-2, 0):]]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %s", day)
+				}
+				entry := RRuleDay{Weekday: weekday}
+				if prefix := day[:len(day)-2]; prefix != "" {
+					n, err := strconv.Atoi(strings.TrimPrefix(prefix, "+"))
+					if err != nil || n == 0 || n < -5 || n > 5 {
+						return nil, fmt.Errorf("invalid BYDAY %s", day)
+					}
+					entry.Ordinal = n
+				}
+				rule.ByDay = append(rule.ByDay, entry)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("invalid BYMONTHDAY %s", day)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, month := range strings.Split(val, ",") {
+				n, err := strconv.Atoi(month)
+				if err != nil || n < 1 || n > 12 {
+					return nil, fmt.Errorf("invalid BYMONTH %s", month)
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			// Weeks follow the household's week start instead
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, errors.New("FREQ is required")
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, errors.New("COUNT and UNTIL cannot both be set")
+	}
+	for _, day := range rule.ByDay {
+		if day.Ordinal != 0 && rule.Freq != "MONTHLY" && rule.Freq != "YEARLY" {
+			return nil, errors.New("numbered BYDAY is only allowed with MONTHLY or YEARLY")
+		}
+	}
+	return rule, nil
+}
+
+func parseRRuleUntil(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", value, loc); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %s", value)
+}
+
+// Format returns the rule in canonical form, without the "RRULE:" prefix.
+// UNTIL is written as a UTC date-time, or as a date in loc when allDay is
+// set, matching the type of the DTSTART it is used with.
+func (r *RRule) Format(allDay bool, loc *time.Location) string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		if allDay {
+			parts = append(parts, "UNTIL="+r.Until.In(loc).Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+ICalTime(*r.Until))
+		}
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = strings.ToUpper(day.Weekday.String()[:2])
+			if day.Ordinal != 0 {
+				days[i] = strconv.Itoa(day.Ordinal) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, len(r.ByMonth))
+		for i, month := range r.ByMonth {
+			months[i] = int(month)
+		}
+		sort.Ints(months)
+		list := make([]string, len(months))
+		for i, month := range months {
+			list[i] = strconv.Itoa(month)
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(list, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// String returns the rule in canonical form with UNTIL in UTC
+func (r *RRule) String() string {
+	return r.Format(false, time.UTC)
+}
+
+// Next returns the first occurrence after start of a series that begins at
+// start, keeping start's local time of day. weekStart decides where weeks
+// begin for WEEKLY rules with an INTERVAL. It returns false when the rule
+// has no further occurrence, including when COUNT is 1.
+func (r *RRule) Next(start time.Time, weekStart time.Weekday) (time.Time, bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+
+	loc := start.Location()
+	limit := start.AddDate(rruleSearchYears, 0, 0)
+	for day := dateOf(start).AddDate(0, 0, 1); day.Before(limit); day = day.AddDate(0, 0, 1) {
+		if !r.matches(day, start, weekStart) {
+			continue
+		}
+		next := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+		if r.Until != nil && next.After(*r.Until) {
+			return time.Time{}, false
+		}
+		return next, true
+	}
+	return time.Time{}, false
+}
+
+// matches reports whether the calendar day is an occurrence of a series
+// starting at start
+func (r *RRule) matches(day, start time.Time, weekStart time.Weekday) bool {
+	startDay := dateOf(start)
+	switch r.Freq {
+	case "DAILY":
+		if daysBetween(startDay, day)%r.Interval != 0 {
+			return false
+		}
+	case "WEEKLY":
+		weeks := daysBetween(weekOf(startDay, weekStart), weekOf(day, weekStart)) / 7
+		if weeks%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+			return false
+		}
+	case "MONTHLY":
+		months := (day.Year()-startDay.Year())*12 + int(day.Month()) - int(startDay.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && day.Day() != start.Day() {
+			return false
+		}
+	case "YEARLY":
+		if (day.Year()-startDay.Year())%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByMonth) == 0 && day.Month() != start.Month() {
+			return false
+		}
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && day.Day() != start.Day() {
+			return false
+		}
+	}
+
+	if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, day.Month()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, day) {
+		return false
+	}
+	if len(r.ByDay) > 0 && !matchesByDay(r.ByDay, day) {
+		return false
+	}
+	return true
+}
+
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(a, b time.Time) int {
+	return int(dateOf(b).Sub(dateOf(a)).Hours() / 24)
+}
+
+func weekOf(day time.Time, weekStart time.Weekday) time.Time {
+	offset := (int(day.Weekday()) - int(weekStart) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func daysInMonth(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func containsMonth(months []time.Month, month time.Month) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMonthDay(days []int, day time.Time) bool {
+	last := daysInMonth(day)
+	for _, d := range days {
+		if d == day.Day() || (d < 0 && last+d+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesByDay(days []RRuleDay, day time.Time) bool {
+	for _, d := range days {
+		if d.Weekday != day.Weekday() {
+			continue
+		}
+		if d.Ordinal == 0 {
+			return true
+		}
+		nth := (day.Day()-1)/7 + 1
+		nthFromEnd := -((daysInMonth(day)-day.Day())/7 + 1)
+		if d.Ordinal == nth || d.Ordinal == nthFromEnd {
+			return true
+		}
+	}
+	return false
+}
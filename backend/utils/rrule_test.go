@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+
+	tests := []struct {
+		value string
+		want  string // canonical form, or a fragment of the error
+		err   bool
+	}{
+		{value: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{value: "RRULE:freq=weekly;interval=1;byday=mo,fr", want: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{value: "FREQ=MONTHLY;BYDAY=+1MO,-1FR", want: "FREQ=MONTHLY;BYDAY=1MO,-1FR"},
+		{value: "FREQ=YEARLY;BYMONTH=12,3;BYMONTHDAY=-1", want: "FREQ=YEARLY;BYMONTHDAY=-1;BYMONTH=3,12"},
+		{value: "FREQ=DAILY;COUNT=5;WKST=SU", want: "FREQ=DAILY;COUNT=5"},
+		// Date-only UNTIL is the end of that day in the household's zone
+		{value: "FREQ=DAILY;UNTIL=20261031", want: "FREQ=DAILY;UNTIL=20261031T215959Z"},
+		{value: "FREQ=DAILY;UNTIL=20261031T120000", want: "FREQ=DAILY;UNTIL=20261031T100000Z"},
+		{value: "FREQ=DAILY;UNTIL=20261031T120000Z", want: "FREQ=DAILY;UNTIL=20261031T120000Z"},
+
+		{value: "", want: "empty", err: true},
+		{value: "INTERVAL=2", want: "FREQ is required", err: true},
+		{value: "FREQ=HOURLY", want: "unsupported FREQ", err: true},
+		{value: "FREQ=DAILY;INTERVAL=0", want: "INTERVAL", err: true},
+		{value: "FREQ=DAILY;INTERVAL=367", want: "INTERVAL", err: true},
+		{value: "FREQ=DAILY;COUNT=0", want: "COUNT", err: true},
+		{value: "FREQ=DAILY;COUNT=2;UNTIL=20261031", want: "cannot both", err: true},
+		{value: "FREQ=DAILY;FREQ=WEEKLY", want: "duplicate", err: true},
+		{value: "FREQ=DAILY;BYSETPOS=1", want: "unsupported rule part", err: true},
+		{value: "FREQ=DAILY;INTERVAL", want: "invalid rule part", err: true},
+		{value: "FREQ=WEEKLY;BYDAY=XX", want: "invalid BYDAY", err: true},
+		{value: "FREQ=WEEKLY;BYDAY=M", want: "invalid BYDAY", err: true},
+		{value: "FREQ=MONTHLY;BYDAY=6MO", want: "invalid BYDAY", err: true},
+		{value: "FREQ=WEEKLY;BYDAY=1MO", want: "numbered BYDAY", err: true},
+		{value: "FREQ=MONTHLY;BYMONTHDAY=0", want: "invalid BYMONTHDAY", err: true},
+		{value: "FREQ=YEARLY;BYMONTH=13", want: "invalid BYMONTH", err: true},
+		{value: "FREQ=DAILY;UNTIL=tomorrow", want: "invalid UNTIL", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			rule, err := ParseRRule(tt.value, loc)
+			if tt.err {
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Fatalf("error = %v, want one mentioning %q", err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRuleFormatAllDayUntil(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	rule, err := ParseRRule("FREQ=WEEKLY;UNTIL=20261031", loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rule.Format(true, loc); got != "FREQ=WEEKLY;UNTIL=20261031" {
+		t.Errorf("Format(true) = %q", got)
+	}
+}
+
+func TestRRuleNext(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	// A Wednesday morning
+	wednesday := time.Date(2026, time.October, 14, 9, 30, 0, 0, loc)
+
+	tests := []struct {
+		rule      string
+		start     time.Time
+		weekStart time.Weekday
+		want      []string // following occurrences, in order
+		ends      bool     // no occurrence follows the last one in want
+	}{
+		{"FREQ=DAILY;INTERVAL=2", wednesday, time.Monday, []string{"2026-10-16", "2026-10-18", "2026-10-20"}, false},
+		{"FREQ=WEEKLY", wednesday, time.Monday, []string{"2026-10-21", "2026-10-28"}, false},
+		{"FREQ=WEEKLY;BYDAY=MO,FR", wednesday, time.Monday, []string{"2026-10-16", "2026-10-19", "2026-10-23"}, false},
+		// Every other week counts weeks from the household's week start
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", wednesday, time.Monday, []string{"2026-10-26", "2026-11-09"}, false},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=SU", wednesday, time.Sunday, []string{"2026-10-25", "2026-11-08"}, false},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=SU", wednesday, time.Monday, []string{"2026-10-18", "2026-11-01"}, false},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", wednesday, time.Monday, []string{"2026-10-31", "2026-11-30", "2026-12-31", "2027-01-31", "2027-02-28"}, false},
+		{"FREQ=MONTHLY;BYDAY=-1FR", wednesday, time.Monday, []string{"2026-10-30", "2026-11-27", "2026-12-25"}, false},
+		{"FREQ=MONTHLY;BYDAY=1MO", wednesday, time.Monday, []string{"2026-11-02", "2026-12-07"}, false},
+		// Months without the start's day are skipped
+		{"FREQ=MONTHLY", time.Date(2027, time.January, 31, 8, 0, 0, 0, loc), time.Monday, []string{"2027-03-31", "2027-05-31"}, false},
+		{"FREQ=YEARLY", time.Date(2028, time.February, 29, 8, 0, 0, 0, loc), time.Monday, []string{"2032-02-29"}, false},
+		{"FREQ=YEARLY;BYMONTH=1,7;BYMONTHDAY=1", wednesday, time.Monday, []string{"2027-01-01", "2027-07-01", "2028-01-01"}, false},
+		{"FREQ=DAILY;UNTIL=20261016", wednesday, time.Monday, []string{"2026-10-15", "2026-10-16"}, true},
+		{"FREQ=DAILY;COUNT=1", wednesday, time.Monday, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			current := tt.start
+			for _, want := range tt.want {
+				next, ok := rule.Next(current, tt.weekStart)
+				if !ok {
+					t.Fatalf("no occurrence after %s, want %s", current.Format("2006-01-02"), want)
+				}
+				if got := next.Format("2006-01-02"); got != want {
+					t.Fatalf("occurrence after %s = %s, want %s", current.Format("2006-01-02"), got, want)
+				}
+				if next.Hour() != tt.start.Hour() || next.Minute() != tt.start.Minute() || next.Location() != loc {
+					t.Errorf("occurrence %s lost the start's time of day", next)
+				}
+				current = next
+			}
+			if next, ok := rule.Next(current, tt.weekStart); ok && tt.ends {
+				t.Errorf("unexpected occurrence %s", next.Format("2006-01-02"))
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// defaultSigningKey is only acceptable for local development
+const defaultSigningKey = "dev-url-signing-key-change-this-in-production"
+
+var signingKey []byte
+
+// LoadSigningKey configures the key download links are signed with, from
+// URL_SIGNING_KEY. In production (APP_ENV=production) it must be set.
+func LoadSigningKey() error {
+	key := os.Getenv("URL_SIGNING_KEY")
+	if key == "" {
+		if os.Getenv("APP_ENV") == "production" {
+			return errors.New("URL_SIGNING_KEY must be set in production")
+		}
+		key = defaultSigningKey
+	}
+	signingKey = []byte(key)
+	return nil
+}
+
+// SignPath returns the query string that lets anyone holding it fetch path
+// until expires
+func SignPath(path string, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return url.Values{
+		"expires":   {exp},
+		"signature": {pathSignature(path, exp)},
+	}.Encode()
+}
+
+// VerifyPathSignature reports whether the expires and signature query
+// parameters were issued for path by SignPath and have not expired
+func VerifyPathSignature(path string, query url.Values) bool {
+	exp := query.Get("expires")
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(query.Get("signature")), []byte(pathSignature(path, exp)))
+}
+
+func pathSignature(path, expires string) string {
+	mac := hmac.New(sha256.New, signingKey)
+	mac.Write([]byte(path + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
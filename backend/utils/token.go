@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateSecureToken returns a URL-safe random token carrying n bytes of
+// entropy. Like GenerateInviteCode it panics if the system cannot supply
+// random bytes, rather than hand out a guessable secret.
+func GenerateSecureToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("secure token: reading random bytes: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// HashToken returns the hex SHA-256 digest used to store secrets such as
+// pairing codes and refresh tokens. Secrets are high-entropy, so a fast hash
+// is sufficient.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}